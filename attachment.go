@@ -1,36 +1,82 @@
 package goemail
 
 import (
+	"strings"
+
 	"github.com/jimtsao/go-email/base64"
 	"github.com/jimtsao/go-email/header"
 	"github.com/jimtsao/go-email/mime"
+	"github.com/jimtsao/go-email/syntax"
 )
 
 type Attachment struct {
-	Inline    bool // inline vs attachment
-	Filename  string
-	ContentID string // for inline ref, eg <img src="cid:[ContentID]" />
-	Data      []byte
+	Inline      bool // inline vs attachment
+	Filename    string
+	ContentID   string             // for inline ref, eg <img src="cid:[ContentID]" />
+	ContentType string             // optional, detected from Data if empty
+	Params      []header.MIMEParam // optional Content-Type parameters, eg charset
+	Data        []byte
+	Message     *mime.Entity // attached as message/rfc822 in place of Data, eg a forwarded message
 }
 
 // Entity converts to mime.Entity form
+//
+// Data is base64 encoded, except for message/* content types
+// which RFC 2046 restricts to 7bit or 8bit encoding
 func (a *Attachment) Entity() *mime.Entity {
-	var ctype header.Header
-	ct, cs := mime.DetectContentType(a.Data)
-	if cs == "" {
-		ctype = header.NewContentType(ct, nil)
-	} else {
-		ctype = header.NewContentType(ct, header.NewMIMEParams("charset", cs))
+	if a.Message != nil {
+		m := mime.NewMessageRFC822(a.Message)
+		m.Headers = []header.Header{
+			m.Headers[0],
+			header.NewContentDisposition(a.Inline, a.Filename, nil),
+			m.Headers[1],
+		}
+		if a.ContentID != "" {
+			m.Headers = append(m.Headers, header.NewContentID(a.ContentID))
+		}
+		return m
+	}
+
+	ct, cs := a.ContentType, ""
+	if ct == "" {
+		ct, cs = mime.DetectContentType(a.Data)
+	}
+	params := a.Params
+	if cs != "" && !hasParam(params, "charset") {
+		params = append(header.NewMIMEParams("charset", cs), params...)
+	}
+	ctype := header.NewContentType(ct, params)
+
+	isMessage := strings.HasPrefix(strings.ToLower(ct), "message/")
+	cte := "base64"
+	if isMessage {
+		cte = "7bit"
+		if !syntax.IsASCII(string(a.Data)) {
+			cte = "8bit"
+		}
 	}
+
 	hh := []header.Header{
 		ctype,
 		header.NewContentDisposition(a.Inline, a.Filename, nil),
-		header.NewContentTransferEncoding("base64"),
+		header.NewContentTransferEncoding(cte),
 	}
 	if a.ContentID != "" {
 		hh = append(hh, header.NewContentID(a.ContentID))
 	}
 
+	if isMessage {
+		return mime.NewEntity(hh, string(a.Data))
+	}
 	b64Data := base64.EncodeToString(a.Data)
 	return mime.NewEntity(hh, b64Data)
 }
+
+func hasParam(params []header.MIMEParam, attribute string) bool {
+	for _, p := range params {
+		if strings.EqualFold(p.Attribute, attribute) {
+			return true
+		}
+	}
+	return false
+}
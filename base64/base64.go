@@ -42,7 +42,7 @@ func (b *b64Writer) Write(p []byte) (int, error) {
 		if err != nil {
 			break
 		}
-		_, err = b.w.Write([]byte("\n"))
+		_, err = b.w.Write([]byte("\r\n"))
 		if err != nil {
 			break
 		}
@@ -59,5 +59,6 @@ func EncodeToString(src []byte) string {
 	sb := &strings.Builder{}
 	enc := NewEncoder(sb)
 	enc.Write(src) // error always nil when using strings.Builder
+	enc.Close()    // flush final partial block
 	return sb.String()
 }
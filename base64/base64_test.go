@@ -1,6 +1,7 @@
 package base64_test
 
 import (
+	stdbase64 "encoding/base64"
 	"strings"
 	"testing"
 
@@ -16,8 +17,17 @@ func TestEncoding(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
-	want := "Zm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9v\n" +
-		"Zm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9v\n" +
+	want := "Zm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9v\r\n" +
+		"Zm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9v\r\n" +
 		"Zm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9vZm9v"
 	assert.Equal(t, want, sb.String())
 }
+
+func TestEncodeToString(t *testing.T) {
+	for _, s := range []string{"", "f", "fo", "foo", "foob", strings.Repeat("foo", 30) + "b"} {
+		got := base64.EncodeToString([]byte(s))
+		dec, err := stdbase64.StdEncoding.DecodeString(strings.ReplaceAll(got, "\r\n", ""))
+		assert.NoError(t, err, s)
+		assert.Equal(t, s, string(dec))
+	}
+}
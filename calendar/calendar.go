@@ -0,0 +1,218 @@
+// package calendar builds iCalendar (RFC 5545) objects and sends them as
+// scheduling messages using the iCalendar Message-Based Interoperability
+// Protocol (iMIP, RFC 6047)
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jimtsao/go-email/header"
+)
+
+// Method is the iTIP (RFC 5546) scheduling method of a calendar
+type Method string
+
+const (
+	MethodPublish Method = "PUBLISH" // publish calendar, no interaction expected
+	MethodRequest Method = "REQUEST" // invite attendees or update an event
+	MethodReply   Method = "REPLY"   // attendee response to a request
+	MethodCancel  Method = "CANCEL"  // cancel event or remove attendees
+)
+
+// DefaultProdID identifies the product which created the calendar
+const DefaultProdID = "-//jimtsao//go-email//EN"
+
+// maxLineLength is the max content line length in octets, excluding CRLF
+const maxLineLength = 75
+
+// Calendar represents an iCalendar object containing one or more events
+//
+// Syntax:
+//
+//	icalstream = 1*icalobject
+//	icalobject = "BEGIN" ":" "VCALENDAR" CRLF
+//	             icalbody
+//	             "END" ":" "VCALENDAR" CRLF
+//	icalbody   = calprops component
+//	calprops   = *( prodid / version / calscale / method / x-prop / iana-prop )
+//	component  = 1*( eventc / todoc / journalc / freebusyc / timezonec /
+//	             iana-comp / x-comp )
+type Calendar struct {
+	ProdID string // defaults to DefaultProdID
+	Method Method
+	Events []*Event
+	// Source supplies the DTSTAMP of events without Stamp, and the
+	// boundaries of NewInvite. Set a seeded source for reproducible
+	// output, see header.NewSeededSource
+	Source *header.Source
+}
+
+// Validate checks calendar and its events meet the
+// requirements of iCalendar and the scheduling method
+func (c *Calendar) Validate() error {
+	switch c.Method {
+	case "", MethodPublish, MethodRequest, MethodReply, MethodCancel:
+	default:
+		return fmt.Errorf("calendar: unsupported method %q", c.Method)
+	}
+	if len(c.Events) == 0 {
+		return errors.New("calendar: must contain at least 1 event")
+	}
+
+	for _, e := range c.Events {
+		if err := e.validate(c.Method); err != nil {
+			return fmt.Errorf("calendar: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// String returns the iCalendar object, with
+// content lines folded at 75 octets
+func (c *Calendar) String() string {
+	w := &writer{}
+	now := c.Source.Now()
+	prodID := c.ProdID
+	if prodID == "" {
+		prodID = DefaultProdID
+	}
+
+	w.line("BEGIN", nil, "VCALENDAR")
+	w.line("PRODID", nil, prodID)
+	w.line("VERSION", nil, "2.0")
+	w.line("CALSCALE", nil, "GREGORIAN")
+	if c.Method != "" {
+		w.line("METHOD", nil, string(c.Method))
+	}
+
+	// time zone definitions referenced by events
+	zones := map[string]*time.Location{}
+	for _, e := range c.Events {
+		for _, t := range []time.Time{e.Start, e.End} {
+			if isZoned(t) && !e.AllDay {
+				zones[t.Location().String()] = t.Location()
+			}
+		}
+	}
+	names := make([]string, 0, len(zones))
+	for name := range zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		year := now.Year()
+		for _, e := range c.Events {
+			if e.Start.Location().String() == name {
+				year = e.Start.Year()
+				break
+			}
+		}
+		writeTimezone(w, zones[name], year)
+	}
+
+	for _, e := range c.Events {
+		e.write(w, c.Method, now)
+	}
+
+	w.line("END", nil, "VCALENDAR")
+	return w.String()
+}
+
+// param represents a property parameter
+type param struct {
+	name  string
+	value string
+}
+
+// writer writes folded content lines
+type writer struct {
+	sb strings.Builder
+}
+
+// line writes content line, folding every 75 octets without
+// splitting multi-octet UTF-8 characters
+//
+// Syntax:
+//
+//	contentline = name *(";" param ) ":" value CRLF
+func (w *writer) line(name string, params []param, value string) {
+	s := name
+	for _, p := range params {
+		s += ";" + p.name + "=" + p.value
+	}
+	s += ":" + value
+
+	limit := maxLineLength
+	for len(s) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+		w.sb.WriteString(s[:i] + "\r\n ")
+		s = s[i:]
+		// continuation lines begin with a space
+		limit = maxLineLength - 1
+	}
+	w.sb.WriteString(s + "\r\n")
+}
+
+func (w *writer) String() string {
+	return w.sb.String()
+}
+
+// escapeText escapes TEXT property values
+//
+// Syntax:
+//
+//	text       = *(TSAFE-CHAR / ":" / DQUOTE / ESCAPED-CHAR)
+//	ESCAPED-CHAR = ("\\" / "\;" / "\," / "\N" / "\n")
+var escapeText = strings.NewReplacer(
+	`\`, `\\`,
+	`;`, `\;`,
+	`,`, `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+// quoteParam returns parameter value, quoted if it contains
+// characters not permitted in paramtext. DQUOTE is not permitted
+// in parameter values and is replaced with a single quote
+//
+// Syntax:
+//
+//	param-value   = paramtext / quoted-string
+//	paramtext     = *SAFE-CHAR
+//	quoted-string = DQUOTE *QSAFE-CHAR DQUOTE
+func quoteParam(s string) string {
+	s = strings.ReplaceAll(s, `"`, "'")
+	if strings.ContainsAny(s, ":;,") {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+// isZoned reports whether t should be output with a TZID
+// parameter, rather than as UTC time
+func isZoned(t time.Time) bool {
+	name := t.Location().String()
+	return name != "UTC" && name != "Local" && name != ""
+}
+
+// formatDateTime returns DATE-TIME value and any TZID parameter.
+// Times not in a named location are converted to UTC
+func formatDateTime(t time.Time) (string, []param) {
+	if isZoned(t) {
+		return t.Format("20060102T150405"), []param{{"TZID", quoteParam(t.Location().String())}}
+	}
+	return t.UTC().Format("20060102T150405Z"), nil
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("20060102")
+}
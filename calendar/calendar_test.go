@@ -0,0 +1,163 @@
+package calendar_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/jimtsao/go-email/calendar"
+	"github.com/jimtsao/go-email/header"
+	"github.com/stretchr/testify/assert"
+)
+
+func event() *calendar.Event {
+	return &calendar.Event{
+		UID:       "1@a.com",
+		Stamp:     time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Start:     time.Date(2000, time.January, 2, 9, 0, 0, 0, time.UTC),
+		End:       time.Date(2000, time.January, 2, 10, 0, 0, 0, time.UTC),
+		Summary:   "Planning",
+		Organizer: calendar.Attendee{Name: "Alice", Email: "alice@a.com"},
+		Attendees: []calendar.Attendee{{Email: "bob@b.com", RSVP: true}},
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	c := &calendar.Calendar{Method: calendar.MethodRequest, Events: []*calendar.Event{event()}}
+	assert.NoError(t, c.Validate())
+	want := "BEGIN:VCALENDAR\r\n" +
+		"PRODID:-//jimtsao//go-email//EN\r\n" +
+		"VERSION:2.0\r\n" +
+		"CALSCALE:GREGORIAN\r\n" +
+		"METHOD:REQUEST\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:1@a.com\r\n" +
+		"SEQUENCE:0\r\n" +
+		"DTSTAMP:20000101T000000Z\r\n" +
+		"DTSTART:20000102T090000Z\r\n" +
+		"DTEND:20000102T100000Z\r\n" +
+		"SUMMARY:Planning\r\n" +
+		"ORGANIZER;CN=Alice:mailto:alice@a.com\r\n" +
+		"ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:bob@b.\r\n" +
+		" com\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	assert.Equal(t, want, c.String())
+
+	// stamp from source, name-addr written as addr-spec
+	src := header.NewSeededSource(1, time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC))
+	ev := event()
+	ev.Stamp = time.Time{}
+	ev.Attendees = []calendar.Attendee{{Email: "Bob <bob@b.com>"}}
+	c2 := &calendar.Calendar{Method: calendar.MethodRequest, Events: []*calendar.Event{ev}, Source: src}
+	assert.NoError(t, c2.Validate())
+	assert.Contains(t, c2.String(), "\r\nDTSTAMP:20010203T040506Z\r\n")
+	assert.Contains(t, c2.String(), "\r\nATTENDEE;CN=Bob;ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION:mailto:bob@b.com\r\n")
+
+	// cancel
+	c.Method = calendar.MethodCancel
+	assert.Contains(t, c.String(), "\r\nSTATUS:CANCELLED\r\n")
+
+	// validation
+	tcs := []struct {
+		method calendar.Method
+		modify func(e *calendar.Event)
+	}{
+		{"ADD", func(e *calendar.Event) {}},
+		{calendar.MethodRequest, func(e *calendar.Event) { e.UID = "" }},
+		{calendar.MethodRequest, func(e *calendar.Event) { e.Start = time.Time{} }},
+		{calendar.MethodRequest, func(e *calendar.Event) { e.End = e.Start.Add(-time.Hour) }},
+		{calendar.MethodRequest, func(e *calendar.Event) { e.Organizer = calendar.Attendee{} }},
+		{calendar.MethodRequest, func(e *calendar.Event) { e.Attendees = nil }},
+		{calendar.MethodReply, func(e *calendar.Event) { e.Attendees = append(e.Attendees, e.Organizer) }},
+		{calendar.MethodRequest, func(e *calendar.Event) { e.Attendees[0].Email = "bob" }},
+	}
+	for i, tc := range tcs {
+		e := event()
+		tc.modify(e)
+		c := &calendar.Calendar{Method: tc.method, Events: []*calendar.Event{e}}
+		assert.Error(t, c.Validate(), i)
+	}
+	assert.Error(t, (&calendar.Calendar{}).Validate(), "no events")
+}
+
+func TestCalendarContentLines(t *testing.T) {
+	e := event()
+	e.Summary = "Q2, Q3; review \\ plan"
+	e.Description = "line one\nline two"
+	e.Location = strings.Repeat("é", 40)
+	e.Attendees[0].Name = "Bob, B"
+	s := (&calendar.Calendar{Events: []*calendar.Event{e}}).String()
+
+	// escaping
+	assert.Contains(t, s, "\r\nSUMMARY:Q2\\, Q3\\; review \\\\ plan\r\n")
+	assert.Contains(t, s, "\r\nDESCRIPTION:line one\\nline two\r\n")
+	assert.Contains(t, s, "\r\nATTENDEE;CN=\"Bob, B\";")
+
+	// folding at 75 octets without splitting characters
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, line)
+		assert.True(t, strings.ToValidUTF8(line, "?") == line, line)
+	}
+	assert.Contains(t, s, "\r\nLOCATION:"+strings.Repeat("é", 33)+"\r\n "+strings.Repeat("é", 7)+"\r\n")
+}
+
+func TestCalendarAllDay(t *testing.T) {
+	e := event()
+	e.AllDay = true
+	e.End = time.Time{}
+	e.Recurrence = []string{"FREQ=YEARLY"}
+	s := (&calendar.Calendar{Events: []*calendar.Event{e}}).String()
+	assert.Contains(t, s, "\r\nDTSTART;VALUE=DATE:20000102\r\n"+
+		"DTEND;VALUE=DATE:20000103\r\n"+
+		"RRULE:FREQ=YEARLY\r\n")
+	assert.NotContains(t, s, "VTIMEZONE")
+}
+
+func TestCalendarTimezone(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	assert.NoError(t, err)
+	e := event()
+	e.Start = time.Date(2024, time.July, 1, 9, 0, 0, 0, london)
+	e.End = e.Start.Add(time.Hour)
+	s := (&calendar.Calendar{Events: []*calendar.Event{e}}).String()
+
+	want := "BEGIN:VTIMEZONE\r\n" +
+		"TZID:Europe/London\r\n" +
+		"BEGIN:DAYLIGHT\r\n" +
+		"DTSTART:20240331T010000\r\n" +
+		"TZOFFSETFROM:+0000\r\n" +
+		"TZOFFSETTO:+0100\r\n" +
+		"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU\r\n" +
+		"TZNAME:BST\r\n" +
+		"END:DAYLIGHT\r\n" +
+		"BEGIN:STANDARD\r\n" +
+		"DTSTART:20241027T020000\r\n" +
+		"TZOFFSETFROM:+0100\r\n" +
+		"TZOFFSETTO:+0000\r\n" +
+		"RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU\r\n" +
+		"TZNAME:GMT\r\n" +
+		"END:STANDARD\r\n" +
+		"END:VTIMEZONE\r\n"
+	assert.Contains(t, s, want)
+	assert.Contains(t, s, "\r\nDTSTART;TZID=Europe/London:20240701T090000\r\n"+
+		"DTEND;TZID=Europe/London:20240701T100000\r\n")
+
+	// fixed offset
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NoError(t, err)
+	e.Start = e.Start.In(tokyo)
+	e.End = time.Time{}
+	s = (&calendar.Calendar{Events: []*calendar.Event{e}}).String()
+	want = "BEGIN:VTIMEZONE\r\n" +
+		"TZID:Asia/Tokyo\r\n" +
+		"BEGIN:STANDARD\r\n" +
+		"DTSTART:19700101T000000\r\n" +
+		"TZOFFSETFROM:+0900\r\n" +
+		"TZOFFSETTO:+0900\r\n" +
+		"TZNAME:JST\r\n" +
+		"END:STANDARD\r\n" +
+		"END:VTIMEZONE\r\n"
+	assert.Contains(t, s, want)
+}
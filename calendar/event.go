@@ -0,0 +1,207 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"time"
+)
+
+// Role is the participation role of an attendee
+type Role string
+
+const (
+	RoleChair          Role = "CHAIR"
+	RoleRequired       Role = "REQ-PARTICIPANT"
+	RoleOptional       Role = "OPT-PARTICIPANT"
+	RoleNonParticipant Role = "NON-PARTICIPANT"
+)
+
+// PartStat is the participation status of an attendee
+type PartStat string
+
+const (
+	PartStatNeedsAction PartStat = "NEEDS-ACTION"
+	PartStatAccepted    PartStat = "ACCEPTED"
+	PartStatDeclined    PartStat = "DECLINED"
+	PartStatTentative   PartStat = "TENTATIVE"
+	PartStatDelegated   PartStat = "DELEGATED"
+)
+
+// Attendee represents a calendar user, as either
+// the organizer or an attendee of an event
+type Attendee struct {
+	Name     string
+	Email    string
+	Role     Role     // attendee only, defaults to REQ-PARTICIPANT
+	PartStat PartStat // attendee only, defaults to NEEDS-ACTION
+	RSVP     bool     // attendee only, response requested
+}
+
+// Event represents a VEVENT calendar component
+//
+// Start and End times in a named location, such as one returned by
+// time.LoadLocation, are output with a TZID parameter and matching
+// VTIMEZONE definition. Other times are converted to UTC
+//
+// Syntax:
+//
+//	eventc     = "BEGIN" ":" "VEVENT" CRLF
+//	             eventprop *alarmc
+//	             "END" ":" "VEVENT" CRLF
+//	eventprop  = *( dtstamp / uid / dtstart / class / created / description /
+//	             geo / last-mod / location / organizer / priority / seq /
+//	             status / summary / transp / url / recurid / rrule /
+//	             dtend / duration / attach / attendee / categories /
+//	             comment / contact / exdate / rstatus / related /
+//	             resources / rdate / x-prop / iana-prop )
+type Event struct {
+	UID         string    // globally unique, unchanged across updates
+	Sequence    int       // incremented with each significant update
+	Stamp       time.Time // DTSTAMP, defaults to current time of Calendar.Source
+	Start       time.Time
+	End         time.Time // optional, defaults to Start for timed events
+	AllDay      bool      // Start and End are dates, End is exclusive
+	Summary     string
+	Description string
+	Location    string
+	Status      string // TENTATIVE, CONFIRMED or CANCELLED
+	Organizer   Attendee
+	Attendees   []Attendee
+	// Recurrence contains RRULE values, eg "FREQ=WEEKLY;COUNT=10"
+	Recurrence []string
+}
+
+// address returns display name and addr-spec of Email, which may be
+// a name-addr such as "Bob <bob@b.com>". Name takes precedence over
+// the display name of Email
+func (a Attendee) address() (string, string) {
+	addr, err := mail.ParseAddress(a.Email)
+	if err != nil {
+		return a.Name, a.Email
+	}
+	if a.Name != "" {
+		return a.Name, addr.Address
+	}
+	return addr.Name, addr.Address
+}
+
+func (e *Event) validate(method Method) error {
+	if e.UID == "" {
+		return errors.New("event: missing UID")
+	}
+	if e.Start.IsZero() {
+		return fmt.Errorf("event %s: missing start", e.UID)
+	}
+	if !e.End.IsZero() && e.End.Before(e.Start) {
+		return fmt.Errorf("event %s: end before start", e.UID)
+	}
+	if e.Sequence < 0 {
+		return fmt.Errorf("event %s: invalid sequence %d", e.UID, e.Sequence)
+	}
+
+	// iTIP scheduling requirements
+	if method != "" && method != MethodPublish && e.Organizer.Email == "" {
+		return fmt.Errorf("event %s: %s requires organizer", e.UID, method)
+	}
+	if (method == MethodRequest || method == MethodCancel) && len(e.Attendees) == 0 {
+		return fmt.Errorf("event %s: %s requires at least 1 attendee", e.UID, method)
+	}
+	if method == MethodReply && len(e.Attendees) != 1 {
+		return fmt.Errorf("event %s: %s requires exactly 1 attendee", e.UID, method)
+	}
+
+	for _, a := range append([]Attendee{e.Organizer}, e.Attendees...) {
+		if a.Email == "" {
+			continue
+		}
+		if _, err := mail.ParseAddress(a.Email); err != nil {
+			return fmt.Errorf("event %s: invalid address %q: %w", e.UID, a.Email, err)
+		}
+	}
+
+	return nil
+}
+
+func (e *Event) write(w *writer, method Method, now time.Time) {
+	stamp := e.Stamp
+	if stamp.IsZero() {
+		stamp = now
+	}
+
+	w.line("BEGIN", nil, "VEVENT")
+	w.line("UID", nil, e.UID)
+	w.line("SEQUENCE", nil, strconv.Itoa(e.Sequence))
+	w.line("DTSTAMP", nil, stamp.UTC().Format("20060102T150405Z"))
+
+	if e.AllDay {
+		// end date is exclusive, so must be at least the following day
+		end := e.End
+		if formatDate(end) <= formatDate(e.Start) {
+			end = e.Start.AddDate(0, 0, 1)
+		}
+		w.line("DTSTART", []param{{"VALUE", "DATE"}}, formatDate(e.Start))
+		w.line("DTEND", []param{{"VALUE", "DATE"}}, formatDate(end))
+	} else {
+		v, p := formatDateTime(e.Start)
+		w.line("DTSTART", p, v)
+		if !e.End.IsZero() {
+			v, p = formatDateTime(e.End)
+			w.line("DTEND", p, v)
+		}
+	}
+	for _, r := range e.Recurrence {
+		w.line("RRULE", nil, r)
+	}
+
+	if e.Summary != "" {
+		w.line("SUMMARY", nil, escapeText.Replace(e.Summary))
+	}
+	if e.Description != "" {
+		w.line("DESCRIPTION", nil, escapeText.Replace(e.Description))
+	}
+	if e.Location != "" {
+		w.line("LOCATION", nil, escapeText.Replace(e.Location))
+	}
+
+	status := e.Status
+	if status == "" && method == MethodCancel {
+		status = "CANCELLED"
+	}
+	if status != "" {
+		w.line("STATUS", nil, status)
+	}
+
+	if e.Organizer.Email != "" {
+		var params []param
+		name, addr := e.Organizer.address()
+		if name != "" {
+			params = append(params, param{"CN", quoteParam(name)})
+		}
+		w.line("ORGANIZER", params, "mailto:"+addr)
+	}
+
+	for _, a := range e.Attendees {
+		role, partstat := a.Role, a.PartStat
+		if role == "" {
+			role = RoleRequired
+		}
+		if partstat == "" {
+			partstat = PartStatNeedsAction
+		}
+
+		var params []param
+		name, addr := a.address()
+		if name != "" {
+			params = append(params, param{"CN", quoteParam(name)})
+		}
+		params = append(params, param{"ROLE", string(role)}, param{"PARTSTAT", string(partstat)})
+		if a.RSVP {
+			params = append(params, param{"RSVP", "TRUE"})
+		}
+		w.line("ATTENDEE", params, "mailto:"+addr)
+	}
+
+	w.line("END", nil, "VEVENT")
+}
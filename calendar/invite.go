@@ -0,0 +1,92 @@
+package calendar
+
+import (
+	"io"
+	"strings"
+
+	"github.com/jimtsao/go-email/base64"
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+	"github.com/jimtsao/go-email/syntax"
+)
+
+// Entity returns the calendar as a text/calendar entity with
+// a method parameter matching the calendar method (RFC 6047)
+func (c *Calendar) Entity() *mime.Entity {
+	return c.entity(c.String())
+}
+
+// entity returns the text/calendar entity of content, the output of String
+func (c *Calendar) entity(content string) *mime.Entity {
+	params := header.NewMIMEParams("charset", "utf-8")
+	if c.Method != "" {
+		params = append(params, header.MIMEParam{Attribute: "method", Value: string(c.Method)})
+	}
+	return textEntity("text/calendar", params, content)
+}
+
+// Attachment returns the calendar as an application/ics attachment
+// named filename, for clients which do not recognise text/calendar parts
+func (c *Calendar) Attachment(filename string) *mime.Entity {
+	return attachment(filename, c.String())
+}
+
+// attachment returns the application/ics entity of content, the output of String
+func attachment(filename string, content string) *mime.Entity {
+	return mime.NewEntity([]header.Header{
+		header.NewContentType("application/ics", header.NewMIMEParams("name", filename)),
+		header.NewContentDisposition(false, filename, nil),
+		header.NewContentTransferEncoding("base64"),
+	}, base64.EncodeToString([]byte(content)))
+}
+
+// NewInvite returns a scheduling message in the structure
+// expected by common mail clients:
+//
+//	multipart/mixed
+//	├── multipart/alternative
+//	│   ├── text/plain
+//	│   ├── text/html (omitted if html is empty)
+//	│   └── text/calendar; method=...
+//	└── application/ics (invite.ics)
+func NewInvite(headers []header.Header, text string, html string, cal *Calendar) *mime.Entity {
+	utf8 := header.NewMIMEParams("charset", "utf-8")
+	alt := []*mime.Entity{textEntity("text/plain", utf8, text)}
+	if html != "" {
+		alt = append(alt, textEntity("text/html", utf8, html))
+	}
+	// both parts must carry the same DTSTAMP, which may be taken from Source
+	content := cal.String()
+	alt = append(alt, cal.entity(content))
+
+	var src io.Reader
+	if cal.Source != nil {
+		src = cal.Source
+	}
+	return mime.NewMultipartFrom(src, "mixed", headers, []*mime.Entity{
+		mime.NewMultipartFrom(src, "alternative", nil, alt),
+		attachment("invite.ics", content),
+	})
+}
+
+// textEntity returns text entity, base64 encoded if it contains
+// non-ascii characters or lines exceeding 998 octets
+func textEntity(mediatype string, params []header.MIMEParam, s string) *mime.Entity {
+	cte, body := "7bit", s
+	if !syntax.IsASCII(s) || hasLongLine(s) {
+		cte, body = "base64", base64.EncodeToString([]byte(s))
+	}
+	return mime.NewEntity([]header.Header{
+		header.NewContentType(mediatype, params),
+		header.NewContentTransferEncoding(cte),
+	}, body)
+}
+
+func hasLongLine(s string) bool {
+	for _, line := range strings.Split(s, "\n") {
+		if len(line) > 998 {
+			return true
+		}
+	}
+	return false
+}
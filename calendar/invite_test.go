@@ -0,0 +1,112 @@
+package calendar_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimtsao/go-email/calendar"
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInvite(t *testing.T) {
+	c := &calendar.Calendar{Method: calendar.MethodRequest, Events: []*calendar.Event{event()}}
+	invite := calendar.NewInvite([]header.Header{
+		header.Address{Field: header.AddressFrom, Value: "alice@a.com"},
+		header.Subject("Invitation: Planning"),
+	}, "You are invited", "<p>You are invited</p>", c)
+
+	e, err := mime.ReadEntity(strings.NewReader(invite.String()))
+	assert.NoError(t, err)
+	mediatype, _ := e.ContentType()
+	assert.Equal(t, "multipart/mixed", mediatype)
+	assert.Len(t, e.Parts(), 2)
+
+	// alternative
+	alt := e.Parts()[0]
+	mediatype, _ = alt.ContentType()
+	assert.Equal(t, "multipart/alternative", mediatype)
+	assert.Len(t, alt.Parts(), 3)
+	var types []string
+	for _, p := range alt.Parts() {
+		mediatype, _ := p.ContentType()
+		types = append(types, mediatype)
+	}
+	assert.Equal(t, []string{"text/plain", "text/html", "text/calendar"}, types)
+	_, params := alt.Parts()[2].ContentType()
+	assert.Equal(t, "REQUEST", params["method"])
+	assert.Equal(t, "utf-8", params["charset"])
+	content, err := alt.Parts()[2].Content()
+	assert.NoError(t, err)
+	assert.Equal(t, c.String(), string(content))
+
+	// attachment
+	ics := e.Parts()[1]
+	mediatype, _ = ics.ContentType()
+	assert.Equal(t, "application/ics", mediatype)
+	assert.Contains(t, ics.Get("Content-Disposition"), "invite.ics")
+	content, err = ics.Content()
+	assert.NoError(t, err)
+	assert.Equal(t, c.String(), string(content))
+
+	// reproducible with seeded source
+	c.Source = header.NewSeededSource(1, time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC))
+	first := calendar.NewInvite(nil, "You are invited", "", c).String()
+	c.Source = header.NewSeededSource(1, time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, first, calendar.NewInvite(nil, "You are invited", "", c).String())
+
+	// non-ascii calendar is base64 encoded
+	c.Events[0].Summary = "Café"
+	part := c.Entity()
+	assert.Equal(t, "Content-Transfer-Encoding: base64\r\n", part.Headers[1].String())
+}
+
+func TestNewInviteNonASCII(t *testing.T) {
+	// length of calendar is not a multiple of 3, final base64 block is partial
+	ev := event()
+	ev.Summary = "Café"
+	c := &calendar.Calendar{Method: calendar.MethodRequest, Events: []*calendar.Event{ev}}
+	for len(c.String())%3 == 0 {
+		ev.Summary += "!"
+	}
+	invite := calendar.NewInvite(nil, "Vous êtes invité", "", c)
+
+	e, err := mime.ReadEntity(strings.NewReader(invite.String()))
+	assert.NoError(t, err)
+	alt := e.Parts()[0]
+	assert.Len(t, alt.Parts(), 2)
+	for i, want := range []string{"Vous êtes invité", c.String()} {
+		assert.Equal(t, "base64", alt.Parts()[i].Get("Content-Transfer-Encoding"))
+		content, err := alt.Parts()[i].Content()
+		assert.NoError(t, err)
+		assert.Equal(t, want, string(content))
+	}
+
+	content, err := e.Parts()[1].Content()
+	assert.NoError(t, err)
+	assert.Equal(t, c.String(), string(content))
+}
+
+func TestNewInviteStamp(t *testing.T) {
+	// clock advances on each call, both parts must share one DTSTAMP
+	now := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	src := &header.Source{Clock: func() time.Time {
+		now = now.Add(time.Hour)
+		return now
+	}}
+	ev := event()
+	ev.Stamp = time.Time{}
+	c := &calendar.Calendar{Method: calendar.MethodRequest, Events: []*calendar.Event{ev}, Source: src}
+	invite := calendar.NewInvite(nil, "You are invited", "", c)
+
+	e, err := mime.ReadEntity(strings.NewReader(invite.String()))
+	assert.NoError(t, err)
+	inline, err := e.Parts()[0].Parts()[1].Content()
+	assert.NoError(t, err)
+	attached, err := e.Parts()[1].Content()
+	assert.NoError(t, err)
+	assert.Contains(t, string(inline), "DTSTAMP:20000101T010000Z\r\n")
+	assert.Equal(t, string(inline), string(attached))
+}
@@ -0,0 +1,276 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jimtsao/go-email/mime"
+)
+
+// ErrNoCalendar is returned when an entity does not contain a calendar
+var ErrNoCalendar = errors.New("calendar: no calendar found")
+
+// isCalendar reports whether mediatype is an iCalendar media type
+func isCalendar(mediatype string) bool {
+	return mediatype == "text/calendar" || mediatype == "application/ics"
+}
+
+// property is a parsed content line
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse parses an iCalendar object. Content lines are unfolded,
+// text values unescaped and VEVENT components read into Events,
+// other components such as VTIMEZONE are skipped. Times with a TZID
+// parameter are loaded using time.LoadLocation, falling back to UTC
+// if the location is unknown
+func Parse(s string) (*Calendar, error) {
+	props, err := parseLines(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(props) == 0 || props[0].name != "BEGIN" || !strings.EqualFold(props[0].value, "VCALENDAR") {
+		return nil, errors.New("calendar: missing BEGIN:VCALENDAR")
+	}
+
+	c := &Calendar{}
+	var stack []string
+	var e *Event
+	for _, p := range props {
+		switch p.name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(p.value))
+			if len(stack) == 2 && stack[1] == "VEVENT" {
+				e = &Event{}
+			}
+			continue
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(p.value) {
+				return nil, fmt.Errorf("calendar: unexpected END:%s", p.value)
+			}
+			if len(stack) == 2 && e != nil {
+				c.Events = append(c.Events, e)
+				e = nil
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		switch {
+		case len(stack) == 1:
+			switch p.name {
+			case "PRODID":
+				c.ProdID = p.value
+			case "METHOD":
+				c.Method = Method(strings.ToUpper(p.value))
+			}
+		case len(stack) == 2 && e != nil:
+			if err := e.parseProperty(p); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("calendar: missing END:%s", stack[len(stack)-1])
+	}
+
+	return c, nil
+}
+
+func (e *Event) parseProperty(p property) error {
+	var err error
+	switch p.name {
+	case "UID":
+		e.UID = p.value
+	case "SEQUENCE":
+		if e.Sequence, err = strconv.Atoi(p.value); err != nil {
+			return fmt.Errorf("calendar: invalid SEQUENCE %q", p.value)
+		}
+	case "DTSTAMP":
+		e.Stamp, err = parseDateTime(p)
+	case "DTSTART":
+		e.Start, err = parseDateTime(p)
+		e.AllDay = strings.EqualFold(p.params["VALUE"], "DATE")
+	case "DTEND":
+		e.End, err = parseDateTime(p)
+	case "RRULE":
+		e.Recurrence = append(e.Recurrence, p.value)
+	case "SUMMARY":
+		e.Summary = unescapeText(p.value)
+	case "DESCRIPTION":
+		e.Description = unescapeText(p.value)
+	case "LOCATION":
+		e.Location = unescapeText(p.value)
+	case "STATUS":
+		e.Status = strings.ToUpper(p.value)
+	case "ORGANIZER":
+		e.Organizer = parseAttendee(p)
+	case "ATTENDEE":
+		e.Attendees = append(e.Attendees, parseAttendee(p))
+	}
+	if err != nil {
+		return fmt.Errorf("calendar: %s: %w", p.name, err)
+	}
+	return nil
+}
+
+// parseLines unfolds and splits content into properties
+//
+// Syntax:
+//
+//	contentline = name *(";" param ) ":" value CRLF
+//	param       = param-name "=" param-value *("," param-value)
+func parseLines(s string) ([]property, error) {
+	// unfold, accepting bare LF line endings
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\n ", "")
+	s = strings.ReplaceAll(s, "\n\t", "")
+
+	var props []property
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		p, err := parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+		props = append(props, p)
+	}
+	return props, nil
+}
+
+func parseLine(line string) (property, error) {
+	p := property{params: map[string]string{}}
+
+	// name
+	i := strings.IndexAny(line, ";:")
+	if i == -1 {
+		return p, fmt.Errorf("calendar: malformed content line %q", line)
+	}
+	p.name = strings.ToUpper(line[:i])
+
+	// params, values may be quoted and contain ";" or ":"
+	for line[i] == ';' {
+		line = line[i+1:]
+		eq := strings.IndexByte(line, '=')
+		if eq == -1 {
+			return p, fmt.Errorf("calendar: malformed parameter in %s", p.name)
+		}
+		name := strings.ToUpper(line[:eq])
+		line = line[eq+1:]
+
+		var value strings.Builder
+		quoted := false
+		i = 0
+		for ; i < len(line); i++ {
+			c := line[i]
+			if c == '"' {
+				quoted = !quoted
+				continue
+			}
+			if !quoted && (c == ';' || c == ':') {
+				break
+			}
+			value.WriteByte(c)
+		}
+		if i == len(line) {
+			return p, fmt.Errorf("calendar: missing value in %s", p.name)
+		}
+		p.params[name] = value.String()
+	}
+
+	p.value = line[i+1:]
+	return p, nil
+}
+
+// parseAttendee reads calendar user address and parameters
+func parseAttendee(p property) Attendee {
+	addr := p.value
+	if len(addr) >= 7 && strings.EqualFold(addr[:7], "mailto:") {
+		addr = addr[7:]
+	}
+	return Attendee{
+		Name:     p.params["CN"],
+		Email:    addr,
+		Role:     Role(strings.ToUpper(p.params["ROLE"])),
+		PartStat: PartStat(strings.ToUpper(p.params["PARTSTAT"])),
+		RSVP:     strings.EqualFold(p.params["RSVP"], "TRUE"),
+	}
+}
+
+// parseDateTime parses DATE, UTC DATE-TIME and local DATE-TIME
+// with or without a TZID parameter. Floating time is read as UTC
+func parseDateTime(p property) (time.Time, error) {
+	if strings.EqualFold(p.params["VALUE"], "DATE") || len(p.value) == 8 {
+		return time.Parse("20060102", p.value)
+	}
+	if strings.HasSuffix(p.value, "Z") {
+		return time.Parse("20060102T150405Z", p.value)
+	}
+
+	loc := time.UTC
+	if tzid := p.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	return time.ParseInLocation("20060102T150405", p.value, loc)
+}
+
+// unescapeText reverses TEXT value escaping
+func unescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				sb.WriteString("\n")
+			default:
+				sb.WriteByte(s[i])
+			}
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// Find parses each text/calendar or application/ics part of a parsed
+// message, see mime.ReadEntity. ErrNoCalendar is returned if there are none
+func Find(e *mime.Entity) ([]*Calendar, error) {
+	var cals []*Calendar
+	err := e.Walk(func(part *mime.Entity) error {
+		mediatype, _ := part.ContentType()
+		if !isCalendar(mediatype) {
+			return nil
+		}
+		content, err := part.Content()
+		if err != nil {
+			return err
+		}
+		c, err := Parse(string(content))
+		if err != nil {
+			return err
+		}
+		cals = append(cals, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(cals) == 0 {
+		return nil, ErrNoCalendar
+	}
+	return cals, nil
+}
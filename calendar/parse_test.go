@@ -0,0 +1,153 @@
+package calendar_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimtsao/go-email/calendar"
+	"github.com/jimtsao/go-email/mime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	// round trip
+	e := event()
+	e.Description = "line one\nline two; with, escapes \\ " + strings.Repeat("é", 40)
+	e.Attendees[0].Name = "Bob: B"
+	e.Recurrence = []string{"FREQ=WEEKLY;COUNT=4"}
+	london, err := time.LoadLocation("Europe/London")
+	assert.NoError(t, err)
+	e.Start = time.Date(2024, time.July, 1, 9, 0, 0, 0, london)
+	e.End = e.Start.Add(time.Hour)
+	c := &calendar.Calendar{Method: calendar.MethodRequest, Events: []*calendar.Event{e}}
+
+	got, err := calendar.Parse(c.String())
+	assert.NoError(t, err)
+	assert.Equal(t, calendar.DefaultProdID, got.ProdID)
+	assert.Equal(t, calendar.MethodRequest, got.Method)
+	assert.Len(t, got.Events, 1)
+	ev := got.Events[0]
+	assert.Equal(t, e.UID, ev.UID)
+	assert.Equal(t, e.Description, ev.Description)
+	assert.Equal(t, e.Recurrence, ev.Recurrence)
+	assert.Equal(t, e.Organizer, ev.Organizer)
+	assert.Equal(t, calendar.Attendee{
+		Name:     "Bob: B",
+		Email:    "bob@b.com",
+		Role:     calendar.RoleRequired,
+		PartStat: calendar.PartStatNeedsAction,
+		RSVP:     true,
+	}, ev.Attendees[0])
+	assert.True(t, e.Start.Equal(ev.Start))
+	assert.Equal(t, "Europe/London", ev.Start.Location().String())
+	assert.True(t, e.Stamp.Equal(ev.Stamp))
+
+	// all day
+	e.AllDay = true
+	got, err = calendar.Parse(c.String())
+	assert.NoError(t, err)
+	assert.True(t, got.Events[0].AllDay)
+	assert.Equal(t, "2024-07-02", got.Events[0].End.Format("2006-01-02"))
+
+	// malformed
+	for _, s := range []string{
+		"",
+		"BEGIN:VEVENT\r\nEND:VEVENT\r\n",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VEVENT\r\n",
+		"BEGIN:VCALENDAR\r\nno colon\r\nEND:VCALENDAR\r\n",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:tomorrow\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+	} {
+		_, err := calendar.Parse(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParseReply(t *testing.T) {
+	// typical client reply, bare LF, tab folded and quoted parameters
+	msg := "From: Bob <bob@b.com>\n" +
+		"To: alice@a.com\n" +
+		"Subject: Accepted: Planning\n" +
+		"Content-Type: multipart/alternative; boundary=\"b1\"\n" +
+		"\n" +
+		"--b1\n" +
+		"Content-Type: text/plain; charset=utf-8\n" +
+		"\n" +
+		"Bob has accepted this invitation.\n" +
+		"--b1\n" +
+		"Content-Type: text/calendar; charset=\"utf-8\"; method=REPLY\n" +
+		"Content-Transfer-Encoding: 7bit\n" +
+		"\n" +
+		"BEGIN:VCALENDAR\n" +
+		"PRODID:-//Example Corp//Calendar//EN\n" +
+		"VERSION:2.0\n" +
+		"METHOD:REPLY\n" +
+		"BEGIN:VEVENT\n" +
+		"DTSTART:20000102T090000Z\n" +
+		"DTSTAMP:20000101T120000Z\n" +
+		"ORGANIZER;CN=Alice:mailto:alice@a.com\n" +
+		"UID:1@a.com\n" +
+		"ATTENDEE;CUTYPE=INDIVIDUAL;ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED;CN=\"Bob;\n" +
+		"\t B\";X-NUM-GUESTS=0:mailto:BOB@b.com\n" +
+		"SEQUENCE:0\n" +
+		"SUMMARY:Planning\n" +
+		"END:VEVENT\n" +
+		"END:VCALENDAR\n" +
+		"--b1--\n"
+
+	e, err := mime.ReadEntity(strings.NewReader(msg))
+	assert.NoError(t, err)
+	r, err := calendar.ParseReply(e)
+	assert.NoError(t, err)
+	assert.Equal(t, "1@a.com", r.UID)
+	assert.Equal(t, "Bob; B", r.Attendee.Name)
+	assert.Equal(t, "BOB@b.com", r.Attendee.Email)
+	assert.Equal(t, calendar.PartStatAccepted, r.Attendee.PartStat)
+
+	// apply to original
+	ev := event()
+	assert.NoError(t, ev.Apply(r))
+	assert.Equal(t, calendar.PartStatAccepted, ev.Attendees[0].PartStat)
+	assert.False(t, ev.Attendees[0].RSVP)
+
+	ev.Sequence = 1
+	assert.Error(t, ev.Apply(r), "outdated sequence")
+	ev.UID = "2@a.com"
+	assert.Error(t, ev.Apply(r), "different event")
+
+	cals, err := calendar.Find(e)
+	assert.NoError(t, err)
+	assert.Len(t, cals, 1)
+
+	// attached as application/ics
+	ics := strings.Replace(msg, "text/calendar; charset=\"utf-8\"; method=REPLY", "application/ics", 1)
+	e, err = mime.ReadEntity(strings.NewReader(ics))
+	assert.NoError(t, err)
+	r, err = calendar.ParseReply(e)
+	assert.NoError(t, err)
+	assert.Equal(t, "1@a.com", r.UID)
+	cals, err = calendar.Find(e)
+	assert.NoError(t, err)
+	assert.Len(t, cals, 1)
+
+	// not a reply
+	_, err = calendar.ParseReply(mime.NewEntity(nil, "hello"))
+	assert.ErrorIs(t, err, calendar.ErrNoCalendar)
+	_, err = calendar.Find(mime.NewEntity(nil, "hello"))
+	assert.ErrorIs(t, err, calendar.ErrNoCalendar)
+}
+
+func TestNewReply(t *testing.T) {
+	original := event()
+	c := calendar.NewReply(original, calendar.Attendee{Name: "Bob", Email: "bob@b.com"}, calendar.PartStatDeclined)
+	assert.NoError(t, c.Validate())
+
+	invite := calendar.NewInvite(nil, "Bob has declined", "", c)
+	e, err := mime.ReadEntity(strings.NewReader(invite.String()))
+	assert.NoError(t, err)
+	r, err := calendar.ParseReply(e)
+	assert.NoError(t, err)
+	assert.NoError(t, original.Apply(r))
+	assert.Equal(t, calendar.PartStatDeclined, original.Attendees[0].PartStat)
+}
@@ -0,0 +1,105 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jimtsao/go-email/mime"
+)
+
+// Reply is an attendee's response to a scheduling request
+type Reply struct {
+	UID      string // identifies original event
+	Sequence int    // sequence of event being responded to
+	Stamp    time.Time
+	Attendee Attendee // responding attendee and their PartStat
+}
+
+// NewReply returns a REPLY calendar in which attendee responds to event
+// with partstat, for sending with NewInvite or Calendar.Entity
+func NewReply(event *Event, attendee Attendee, partstat PartStat) *Calendar {
+	attendee.PartStat = partstat
+	attendee.RSVP = false
+	reply := &Event{
+		UID:       event.UID,
+		Sequence:  event.Sequence,
+		Start:     event.Start,
+		End:       event.End,
+		AllDay:    event.AllDay,
+		Summary:   event.Summary,
+		Organizer: event.Organizer,
+		Attendees: []Attendee{attendee},
+	}
+	return &Calendar{Method: MethodReply, Events: []*Event{reply}}
+}
+
+// ParseReply returns the attendee response contained in a parsed
+// message, see mime.ReadEntity. ErrNoCalendar is returned if the
+// message does not contain a REPLY calendar
+func ParseReply(e *mime.Entity) (*Reply, error) {
+	var reply *Calendar
+	e.Walk(func(part *mime.Entity) error {
+		mediatype, params := part.ContentType()
+		if !isCalendar(mediatype) {
+			return nil
+		}
+		// method parameter is required to match calendar
+		if m := params["method"]; m != "" && !strings.EqualFold(m, string(MethodReply)) {
+			return nil
+		}
+		content, err := part.Content()
+		if err != nil {
+			return nil
+		}
+		if c, err := Parse(string(content)); err == nil && c.Method == MethodReply {
+			reply = c
+			return mime.SkipAll
+		}
+		return nil
+	})
+	if reply == nil {
+		return nil, ErrNoCalendar
+	}
+
+	if len(reply.Events) == 0 {
+		return nil, errors.New("calendar: reply contains no events")
+	}
+	ev := reply.Events[0]
+	if ev.UID == "" {
+		return nil, errors.New("calendar: reply missing UID")
+	}
+	if len(ev.Attendees) != 1 {
+		return nil, fmt.Errorf("calendar: reply must contain exactly 1 attendee, found %d", len(ev.Attendees))
+	}
+
+	return &Reply{
+		UID:      ev.UID,
+		Sequence: ev.Sequence,
+		Stamp:    ev.Stamp,
+		Attendee: ev.Attendees[0],
+	}, nil
+}
+
+// Apply updates the participation status of the responding attendee.
+// Replies to another event, or an older sequence of this event, are
+// rejected. Replies from addresses not invited are added as attendees
+func (e *Event) Apply(r *Reply) error {
+	if r.UID != e.UID {
+		return fmt.Errorf("calendar: reply UID %q does not match event %q", r.UID, e.UID)
+	}
+	if r.Sequence < e.Sequence {
+		return fmt.Errorf("calendar: reply to outdated sequence %d of event %s", r.Sequence, e.UID)
+	}
+
+	for i, a := range e.Attendees {
+		if strings.EqualFold(a.Email, r.Attendee.Email) {
+			e.Attendees[i].PartStat = r.Attendee.PartStat
+			e.Attendees[i].RSVP = false
+			return nil
+		}
+	}
+	e.Attendees = append(e.Attendees, r.Attendee)
+	return nil
+}
@@ -0,0 +1,117 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// transition is a change in UTC offset of a location
+type transition struct {
+	at       time.Time // instant of change
+	from, to int       // offset in seconds east of UTC
+	name     string    // abbreviation after change
+	dst      bool
+}
+
+// writeTimezone writes VTIMEZONE component for loc, using the offset
+// transitions occurring in year to derive yearly recurrence rules
+//
+// Syntax:
+//
+//	timezonec  = "BEGIN" ":" "VTIMEZONE" CRLF
+//	             *( tzid / last-mod / tzurl / x-prop / iana-prop )
+//	             *( standardc / daylightc )
+//	             "END" ":" "VTIMEZONE" CRLF
+//	tzprop     = dtstart / tzoffsetto / tzoffsetfrom /
+//	             [ rrule ] / *( comment / rdate / tzname )
+func writeTimezone(w *writer, loc *time.Location, year int) {
+	w.line("BEGIN", nil, "VTIMEZONE")
+	w.line("TZID", nil, loc.String())
+
+	transitions := findTransitions(loc, year)
+	if len(transitions) == 0 {
+		// fixed offset
+		name, offset := time.Date(year, time.January, 1, 0, 0, 0, 0, loc).Zone()
+		w.line("BEGIN", nil, "STANDARD")
+		w.line("DTSTART", nil, "19700101T000000")
+		w.line("TZOFFSETFROM", nil, formatOffset(offset))
+		w.line("TZOFFSETTO", nil, formatOffset(offset))
+		w.line("TZNAME", nil, escapeText.Replace(name))
+		w.line("END", nil, "STANDARD")
+	}
+
+	for _, t := range transitions {
+		comp := "STANDARD"
+		if t.dst {
+			comp = "DAYLIGHT"
+		}
+		// onset is expressed in local time prior to transition
+		onset := t.at.In(time.FixedZone("", t.from))
+		w.line("BEGIN", nil, comp)
+		w.line("DTSTART", nil, onset.Format("20060102T150405"))
+		w.line("TZOFFSETFROM", nil, formatOffset(t.from))
+		w.line("TZOFFSETTO", nil, formatOffset(t.to))
+		w.line("RRULE", nil, yearlyRule(onset))
+		w.line("TZNAME", nil, escapeText.Replace(t.name))
+		w.line("END", nil, comp)
+	}
+
+	w.line("END", nil, "VTIMEZONE")
+}
+
+// findTransitions returns each offset change of loc during year
+func findTransitions(loc *time.Location, year int) []transition {
+	var res []transition
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	end := start.AddDate(1, 0, 0)
+
+	for day := start; day.Before(end); day = day.Add(24 * time.Hour) {
+		next := day.Add(24 * time.Hour)
+		_, from := day.Zone()
+		_, to := next.Zone()
+		if from == to {
+			continue
+		}
+
+		// narrow down to the second
+		lo, hi := day, next
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, off := mid.Zone(); off == from {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		name, _ := hi.Zone()
+		res = append(res, transition{at: hi, from: from, to: to, name: name, dst: hi.IsDST()})
+	}
+
+	return res
+}
+
+// yearlyRule returns RRULE recurring on the same weekday of month as t,
+// eg second sunday of march, or last sunday of october
+func yearlyRule(t time.Time) string {
+	days := []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+	lastDay := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	nth := (t.Day()-1)/7 + 1
+	if t.Day()+7 > lastDay {
+		nth = -1
+	}
+	return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%d%s", t.Month(), nth, days[t.Weekday()])
+}
+
+// formatOffset returns UTC offset in ("+" / "-") hhmm [ss] form
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+	if seconds%60 != 0 {
+		s += fmt.Sprintf("%02d", seconds%60)
+	}
+	return s
+}
@@ -0,0 +1,112 @@
+// Package charset converts text between UTF-8 and the character sets
+// commonly found in mail, such as ISO-8859-1, windows-1252, Shift_JIS,
+// GB2312 and KOI8-R, using the encodings of golang.org/x/text
+//
+// usage:
+//
+//	text, err := charset.Decode("iso-8859-1", []byte("caf\xe9"))
+//	b, err := charset.Encode("koi8-r", "Привет")
+//	s, err := charset.DecodeHeader("=?shift_jis?b?g2WDWINn?=")
+package charset
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/ianaindex"
+)
+
+// ErrUnsupported is returned for a charset that is unknown or unsupported
+var ErrUnsupported = errors.New("unsupported charset")
+
+// Lookup returns the encoding of charset name, matched case insensitively
+// against IANA registered names and aliases (RFC 2978), then against WHATWG
+// labels for names commonly used in place of them, eg "cp1252" or "sjis".
+// GB2312 is decoded as its superset GBK
+func Lookup(name string) (encoding.Encoding, error) {
+	name = strings.TrimSpace(name)
+	if e, err := ianaindex.MIME.Encoding(name); err == nil && e != nil {
+		return e, nil
+	}
+	if e, err := htmlindex.Get(name); err == nil {
+		return e, nil
+	}
+	return nil, fmt.Errorf("charset: %w %q", ErrUnsupported, name)
+}
+
+// IsUTF8 reports whether name is UTF-8, or its subset us-ascii
+func IsUTF8(name string) bool {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return true
+	}
+	return false
+}
+
+// Decode returns b converted from charset name to UTF-8. Bytes
+// invalid in the charset are replaced by U+FFFD. If name is empty
+// or UTF-8 (see IsUTF8), b is returned as is
+func Decode(name string, b []byte) ([]byte, error) {
+	if name == "" || IsUTF8(name) {
+		return b, nil
+	}
+	e, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	dec, err := e.NewDecoder().Bytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("charset: %s: %w", name, err)
+	}
+	return dec, nil
+}
+
+// Encode returns s converted from UTF-8 to charset name. An error is
+// returned if s contains a character that cannot be represented in it
+func Encode(name string, s string) ([]byte, error) {
+	if strings.EqualFold(name, "us-ascii") {
+		for i := 0; i < len(s); i++ {
+			if s[i] > 127 {
+				return nil, fmt.Errorf("charset: %s: character not representable at offset %d", name, i)
+			}
+		}
+	}
+	if IsUTF8(name) {
+		return []byte(s), nil
+	}
+	e, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := e.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		return nil, fmt.Errorf("charset: %s: %w", name, err)
+	}
+	return enc, nil
+}
+
+// NewReader returns a reader converting r from charset name to UTF-8.
+// It may be used as the CharsetReader of a mime.WordDecoder
+func NewReader(name string, r io.Reader) (io.Reader, error) {
+	if IsUTF8(name) {
+		return r, nil
+	}
+	e, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return e.NewDecoder().Reader(r), nil
+}
+
+var wordDecoder = &mime.WordDecoder{CharsetReader: NewReader}
+
+// DecodeHeader decodes all encoded-words of s (RFC 2047) to UTF-8,
+// accepting any charset supported by Lookup
+func DecodeHeader(s string) (string, error) {
+	return wordDecoder.DecodeHeader(s)
+}
@@ -0,0 +1,66 @@
+package charset_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/jimtsao/go-email/charset"
+	"github.com/stretchr/testify/assert"
+)
+
+var samples = []struct {
+	name    string
+	decoded string
+	encoded string
+}{
+	{"ISO-8859-1", "café", "caf\xe9"},
+	{"windows-1252", "€uro “q”", "\x80uro \x93q\x94"},
+	{"Shift_JIS", "テスト", "\x83e\x83X\x83g"},
+	{"GB2312", "中文", "\xd6\xd0\xce\xc4"},
+	{"KOI8-R", "Привет", "\xf0\xd2\xc9\xd7\xc5\xd4"},
+	{"utf-8", "café", "café"},
+}
+
+func TestDecode(t *testing.T) {
+	for _, c := range samples {
+		got, err := charset.Decode(c.name, []byte(c.encoded))
+		assert.NoError(t, err, c.name)
+		assert.Equal(t, c.decoded, string(got), c.name)
+
+		r, err := charset.NewReader(c.name, bytes.NewReader([]byte(c.encoded)))
+		if assert.NoError(t, err, c.name) {
+			got, _ = io.ReadAll(r)
+			assert.Equal(t, c.decoded, string(got), c.name)
+		}
+	}
+
+	// aliases
+	for _, name := range []string{"latin1", "cp1252", "sjis", "csKOI8R", " US-ASCII "} {
+		_, err := charset.Lookup(name)
+		assert.NoError(t, err, name)
+	}
+
+	_, err := charset.Decode("x-unknown", []byte("a"))
+	assert.True(t, errors.Is(err, charset.ErrUnsupported))
+}
+
+func TestEncode(t *testing.T) {
+	for _, c := range samples {
+		got, err := charset.Encode(c.name, c.decoded)
+		assert.NoError(t, err, c.name)
+		assert.Equal(t, c.encoded, string(got), c.name)
+	}
+
+	for _, name := range []string{"iso-8859-1", "us-ascii", "koi8-r"} {
+		_, err := charset.Encode(name, "中文")
+		assert.Error(t, err, name)
+	}
+}
+
+func TestDecodeHeader(t *testing.T) {
+	got, err := charset.DecodeHeader("=?shift_jis?b?g2WDWINn?= =?koi8-r?q?=F0=D2=C9=D7=C5=D4?= =?windows-1252?q?=80?=")
+	assert.NoError(t, err)
+	assert.Equal(t, "テストПривет€", got)
+}
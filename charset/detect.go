@@ -0,0 +1,240 @@
+package charset
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// Detect guesses the charset of text b, of media type mediatype,
+// in order of precedence:
+//
+//   - byte order mark: utf-8, utf-16be or utf-16le
+//   - escape sequences of iso-2022-jp
+//   - utf-8 if b is valid UTF-8 containing non us-ascii
+//   - charset of a meta element, if mediatype is text/html
+//   - utf-8 if b is us-ascii
+//   - the legacy charset whose decoded text is most plausible,
+//     defaulting to windows-1252
+//
+// Legacy charsets considered are windows-1252, shift_jis, gb2312,
+// euc-kr, koi8-r and windows-1251. Detection of short text is unreliable
+func Detect(b []byte, mediatype string) string {
+	switch {
+	case bytes.HasPrefix(b, []byte("\xef\xbb\xbf")):
+		return "utf-8"
+	case bytes.HasPrefix(b, []byte("\xfe\xff")):
+		return "utf-16be"
+	case bytes.HasPrefix(b, []byte("\xff\xfe")):
+		return "utf-16le"
+	case bytes.Contains(b, []byte("\x1b$B")) || bytes.Contains(b, []byte("\x1b$@")):
+		return "iso-2022-jp"
+	}
+
+	ascii := isASCII(b)
+	if !ascii && utf8.Valid(b) {
+		return "utf-8"
+	}
+	if strings.EqualFold(mediatype, "text/html") {
+		if cs := metaCharset(b); cs != "" {
+			return cs
+		}
+	}
+	if ascii {
+		return "utf-8"
+	}
+
+	best, score := "windows-1252", 0.0
+	for _, c := range candidates {
+		if s := c.score(b); s > score {
+			best, score = c.name, s
+		}
+	}
+	return best
+}
+
+// metaElement matches charset of an html meta element, either
+// <meta charset="x"> or <meta http-equiv content="text/html; charset=x">
+var metaElement = regexp.MustCompile(`(?i)<meta\s[^>]*charset\s*=\s*["']?\s*([a-z0-9_.:-]+)`)
+
+// metaCharset returns charset declared by a meta element within
+// the first 1024 bytes, as per the HTML prescan algorithm
+func metaCharset(b []byte) string {
+	if len(b) > 1024 {
+		b = b[:1024]
+	}
+	m := metaElement.FindSubmatch(b)
+	if m == nil {
+		return ""
+	}
+	name := strings.ToLower(string(m[1]))
+	if _, err := Lookup(name); err != nil {
+		return ""
+	}
+	return name
+}
+
+func isASCII(b []byte) bool {
+	for _, c := range b {
+		if c > 127 {
+			return false
+		}
+	}
+	return true
+}
+
+// candidate is a legacy charset and the plausibility of
+// each non us-ascii rune of text decoded from it
+type candidate struct {
+	name      string
+	enc       encoding.Encoding
+	multibyte bool
+	weight    func(r rune, prev rune, next rune, run int) float64
+}
+
+var candidates = []candidate{
+	{"windows-1252", charmap.Windows1252, false, latinWeight},
+	{"shift_jis", japanese.ShiftJIS, true, japaneseWeight},
+	{"gb2312", simplifiedchinese.GBK, true, chineseWeight},
+	{"euc-kr", korean.EUCKR, true, koreanWeight},
+	{"koi8-r", charmap.KOI8R, false, cyrillicWeight},
+	{"windows-1251", charmap.Windows1251, false, cyrillicWeight},
+}
+
+// score returns the proportion of non us-ascii bytes of b which
+// decode to plausible text, where invalid sequences count against
+func (c candidate) score(b []byte) float64 {
+	high := 0
+	for _, x := range b {
+		if x > 127 {
+			high++
+		}
+	}
+	dec, err := c.enc.NewDecoder().Bytes(b)
+	if err != nil || high == 0 {
+		return 0
+	}
+
+	runes := []rune(string(dec))
+	total, run := 0.0, 0
+	for i, r := range runes {
+		if r < utf8.RuneSelf {
+			run = 0
+			continue
+		}
+		run++
+		var prev, next rune
+		if i > 0 {
+			prev = runes[i-1]
+		}
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		// approximate bytes consumed by rune
+		size := 1.0
+		if c.multibyte && r != utf8.RuneError && !(r >= 0xff61 && r <= 0xff9f) {
+			size = 2
+		}
+		if r == utf8.RuneError {
+			total -= size
+		} else {
+			total += size * c.weight(r, prev, next, run)
+		}
+	}
+	return total / float64(high)
+}
+
+// latinWeight favours accented letters adjacent to us-ascii letters,
+// as in western european text, over long runs of non us-ascii
+func latinWeight(r rune, prev rune, next rune, run int) float64 {
+	switch {
+	case strings.ContainsRune("€‚„…†‡‰‹›‘’“”•–—™«»°£©®", r):
+		return 0.8
+	case r == '×' || r == '÷' || !unicode.IsLetter(r):
+		return 0.1
+	case isASCIILetter(prev) || isASCIILetter(next):
+		return 1
+	case run <= 2:
+		return 0.5
+	}
+	return 0.1
+}
+
+// cyrillicWeight favours common lower case letters in words of
+// plausible length. Letters adjacent to us-ascii letters are unlikely,
+// as cyrillic words are not mixed with latin, but are typical of
+// accented latin text misread as cyrillic
+func cyrillicWeight(r rune, prev rune, next rune, run int) float64 {
+	switch {
+	case run > 20 || !unicode.Is(unicode.Cyrillic, r):
+		return 0
+	case isASCIILetter(prev) || isASCIILetter(next):
+		return 0.1
+	case strings.ContainsRune("оеаинтсрвлкмдпуяыьгзбчйхжшюцщэфъё", r):
+		if strings.ContainsRune("оеаинтсрвл", r) {
+			return 1
+		}
+		return 0.7
+	}
+	return 0.3
+}
+
+// japaneseWeight favours kana, which are distinctive of japanese
+func japaneseWeight(r rune, prev rune, next rune, run int) float64 {
+	switch {
+	case r >= 0x3040 && r <= 0x30ff: // hiragana, katakana
+		return 1
+	case r >= 0x3000 && r <= 0x303f: // punctuation
+		return 0.8
+	case r >= 0x4e00 && r <= 0x9fff: // kanji
+		return 0.6
+	case r >= 0xff01 && r <= 0xff60: // fullwidth forms
+		return 0.5
+	}
+	return 0.1
+}
+
+// chineseWeight favours the most frequently used hanzi
+func chineseWeight(r rune, prev rune, next rune, run int) float64 {
+	switch {
+	case strings.ContainsRune(commonHanzi, r):
+		return 1
+	case r >= 0x3000 && r <= 0x303f, r >= 0xff01 && r <= 0xff5e: // punctuation, fullwidth forms
+		return 0.8
+	case r >= 0x4e00 && r <= 0x9fff:
+		return 0.4
+	}
+	return 0.1
+}
+
+// koreanWeight favours the most frequently used hangul syllables
+func koreanWeight(r rune, prev rune, next rune, run int) float64 {
+	switch {
+	case strings.ContainsRune(commonHangul, r):
+		return 1
+	case r >= 0xac00 && r <= 0xd7a3:
+		return 0.4
+	case r >= 0x3000 && r <= 0x303f:
+		return 0.8
+	}
+	return 0.1
+}
+
+func isASCIILetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// commonHanzi are frequently used simplified chinese characters
+const commonHanzi = "的一是不了在人有我他这个们中来上大为和国地到以说时要就出会可也你对生能而子那得于着下自之年过发后作里用道行所然家种事成方多经么去法学如都同现当没动面起看定天分还进好小部其些主样理心她本前开但因只从想实日军者意无力它与长把机十民第公此已工使情明性知全三又关点正业外将两高间由问很最重并物手应战向头文体政美相见被利什二等产或新己制身果加西斯月话合回特代内信表化老给世位次度门任常先海通教儿原东声提立及比员解水名真论处走义各入几口认条平系气题活尔更别打女变四神总何电数安少报才结反受目太量再感建务做接必场件计管期市直德资命山金指克许统区保至队形社便空决治展马科司五基眼书非则听白却界达光放强即像难且权思王象完设式色路记南品住告类求据程北边死张该交规万取拉格望觉术领共确传师观清今切院让识候带导争运笑飞风步改收根干造言联持组每济车亲极林服快办议往元英士证近失转夫令准布始怎呢存未远叫台单影具罗字爱击流备兵连调深商算质团集百需价花党华城石级整府离况亚请技际约示复病息究线似官火断精满支视消越器容照须九增研写称企八功吗包片史委乎查轻易早曾除农找装广显吧阿李标谈吃图念六引历首医局突专费号尽另周较注语仅考落青随选列武红响虽推势参希古众构房半节土投某案黑维革划敌致陈律足态护七兴派孩验责营星够章音跟志底站严巴例防族供效续施留讲型料终答紧黄绝奇察母京段依批群项故按河米围江织害斗双境客纪采举杀攻父苏密低朝友诉止细愿千值仍男钱破网热助倒育属坐帝限船脸职速刻乐否刚威毛状率甚独球般普怕弹校苦创假久错承印晚兰试股拿脑预谁益阳若哪微尼继送急血惊伤素药适波夜省初喜卫源食险待述陆习置居劳财环排福纳欢雷警获模充负云停木游龙树疑层冷洲冲射略范竟句室异激汉村哈策演简卡罪判担州静退既衣您宗积余痛检差富灵协角占配征修皮挥胜降阶审沉坚善妈刘读啊超免压银买皇养伊怀执副乱抗犯追帮宣佛岁航优怪香著田铁控税左右份穿艺背阵草脚概恶块顿敢守酒岛托央户烈洋哥索胡款靠评版宝座释景顾弟登货互付伯慢欧换闻危忙核暗姐介坏讨丽良序升监临亮露永呼味野架域沙掉括舰鱼杂误湾吉减编楚肯测败屋跑梦散温困剑渐封救贵枪缺楼县尚毫移娘朋画班智亦耳恩短掌恐遗固席松秘谢鲁遇康虑幸均销钟诗藏赶剧票损忽巨炮旧端探湖录叶春乡附吸予礼港雨呀板庭妇归睛饭额含顺输摇招婚脱补谓督毒油疗旅泽材灭逐莫笔亡鲜词圣择寻厂睡博勒烟授诺伦岸奥唐卖俄炸载洛健堂旁宫喝借君禁阴园谋宋避抓荣姑孙逃牙束跳顶玉镇雪午练迫爷篇肉嘴馆凡础洞卷坦牛宁纸诸训私庄祖丝翻暴森塔默握戏隐熟骨访弱蒙歌店鬼软典欲萨伙遭盘爸扩盖弄雄稳忘亿刺拥徒姆杨齐赛趣曲刀床迎冰虚玩析窗醒妻透购替塞努休虎扬途侵刑绿兄迅套贸毕唯谷轮库迹尤竞街促延震弃甲伟麻川申缓潜闪售灯针哲络抵朱埃抱鼓植纯夏忍页杰筑折郑贝尊吴秀混臣雅振染盛怒舞圆搞狂措姓残秋培迷诚宽宇猛摆梅毁伸摩盟末乃悲拍丁赵罚牌挑滚"
+
+// commonHangul are frequently used hangul syllables
+const commonHangul = "이다는의에가하고을를지서기사한로리자대수어도정시일인아니나상것주보있부국전게라요해제들거면만으은습까우여경구안없했적성동그무장회내마소연비개학원때말공생관위세금문계진실모께오할될되던와과중신분및발영방변저선물미좀더또히며"
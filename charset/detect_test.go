@@ -0,0 +1,51 @@
+package charset_test
+
+import (
+	"testing"
+
+	"github.com/jimtsao/go-email/charset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	for _, c := range []struct {
+		charset string
+		text    string
+	}{
+		{"windows-1252", "Le café est très bon, merci beaucoup. Ça va?"},
+		{"windows-1252", "Grüße aus München, schöne Straße"},
+		{"windows-1252", "“Quoted” text – with dashes"},
+		{"shift_jis", "こんにちは、世界。今日はいい天気ですね。"},
+		{"shift_jis", "テスト"},
+		{"gb2312", "你好，世界。今天天气很好。"},
+		{"gb2312", "这是一个测试邮件，请不要回复。"},
+		{"euc-kr", "안녕하세요, 세계. 오늘은 날씨가 좋네요."},
+		{"koi8-r", "Привет, мир. Сегодня хорошая погода."},
+		{"windows-1251", "Привет, мир. Сегодня хорошая погода."},
+	} {
+		b, err := charset.Encode(c.charset, c.text)
+		assert.NoError(t, err, c.text)
+		assert.Equal(t, c.charset, charset.Detect(b, "text/plain"), c.text)
+	}
+
+	for _, c := range []struct {
+		input     string
+		mediatype string
+		want      string
+	}{
+		{"\xef\xbb\xbfcaf\xc3\xa9", "text/plain", "utf-8"},
+		{"\xff\xfeh\x00i\x00", "text/plain", "utf-16le"},
+		{"\xfe\xff\x00h\x00i", "text/plain", "utf-16be"},
+		{"\x1b$B%F%9%H\x1b(B", "text/plain", "iso-2022-jp"},
+		{"plain ascii", "text/plain", "utf-8"},
+		{"café", "text/plain", "utf-8"},
+		{`<meta http-equiv="Content-Type" content="text/html; charset=ISO-8859-1">caf` + "\xe9", "text/html", "iso-8859-1"},
+		{"<META CHARSET='Shift_JIS'>", "text/html", "shift_jis"},
+		{"<meta charset=x-unknown>caf\xe9", "text/html", "windows-1252"},
+		{"<meta charset=koi8-r>caf\xe9", "text/plain", "windows-1252"},
+		{"<meta charset=koi8-r>café", "text/html", "utf-8"},
+		{"caf\xe9 \xe0 la cr\xe8me", "text/plain", "windows-1252"},
+	} {
+		assert.Equal(t, c.want, charset.Detect([]byte(c.input), c.mediatype), c.input)
+	}
+}
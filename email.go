@@ -1,8 +1,16 @@
 package goemail
 
 import (
+	"bytes"
+	"io"
+	"mime/quotedprintable"
+	"strings"
+	"time"
+
+	"github.com/jimtsao/go-email/charset"
 	"github.com/jimtsao/go-email/header"
 	"github.com/jimtsao/go-email/mime"
+	"github.com/jimtsao/go-email/syntax"
 )
 
 // Email is a wrapper around mime.Entity
@@ -15,8 +23,20 @@ type Email struct {
 	Bcc         string // accepts comma-separated list
 	Subject     string // can contain any printable unicode characters
 	Body        string
+	BodyType    string // media type of Body, eg "text/html", detected from Body if empty
+	Text        string // plain text alternative to html Body, or the body if Body is empty
+	Charset     string // eg "iso-8859-1", for Body, Text, Subject and display names it can represent, defaults to utf-8
 	Attachments []*Attachment
-	headers     []header.Header
+	List        *MailingList // List-* headers for bulk and list mail
+	// AutoDate inserts a Date header using Clock,
+	// unless one has been added via AddHeader
+	AutoDate bool
+	Clock    func() time.Time // defaults to Source clock
+	// Source supplies the randomness of generated boundaries and
+	// Content-IDs, and the time if Clock is nil. Set a seeded source
+	// for reproducible output, see header.NewSeededSource
+	Source  *header.Source
+	headers []header.Header
 }
 
 func New() *Email {
@@ -29,9 +49,17 @@ func (e *Email) AddHeader(h header.Header) {
 	e.headers = append(e.headers, h)
 }
 
-// Validate checks syntax of headers for potential errors
-// returns nil if no errors detected
+// Validate checks syntax of headers and conformance of the message
+// as a whole, see mime.CheckMessage, returns nil if no errors detected.
+// Errors are of type *header.Error, describing the field, offset and
+// rule violated, and may be warnings which do not prevent sending,
+// see header.IsWarning
 func (e *Email) Validate() []error {
+	return e.validate(e.entity())
+}
+
+// validate checks email and its composed entity m
+func (e *Email) validate(m *mime.Entity) []error {
 	hh := e.getHeaders()
 	var errs []error
 	for _, h := range hh {
@@ -39,23 +67,80 @@ func (e *Email) Validate() []error {
 			errs = append(errs, err)
 		}
 	}
-	return errs
+	if e.List != nil {
+		if err := e.List.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return append(errs, mime.CheckMessage(m)...)
 }
 
-// Raw produces RFC 5322 and MIME compliant email
+// Raw produces RFC 5322 and MIME compliant email. CR, LF and NUL
+// characters in header values are replaced by spaces so that they cannot
+// inject additional header fields, use Validate to detect them beforehand
+//
+// Raw always produces output, use Bytes to be notified of invalid
+// headers or lines that cannot be kept within the line length limit
 func (e *Email) Raw() string {
+	return e.entity().String()
+}
+
+// Bytes returns the email as per Raw, or an error if the email fails
+// Validate or could not be encoded without producing a malformed
+// message, see mime.Entity.Encode. Warnings do not prevent encoding
+func (e *Email) Bytes() ([]byte, error) {
+	// entity generates boundaries and dates, so is composed once
+	// for the message validated to be the message encoded
+	m := e.entity()
+	var errs ValidationError
+	for _, err := range e.validate(m) {
+		if !header.IsWarning(err) {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	buf := &bytes.Buffer{}
+	if err := m.Encode(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ValidationError contains the errors returned by Validate
+type ValidationError []error
+
+func (v ValidationError) Error() string {
+	s := make([]string, len(v))
+	for i, err := range v {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+// entity composes the mime entity tree of the email
+func (e *Email) entity() *mime.Entity {
 	// create body, inline and attachment entities
 	var body *mime.Entity
 	if e.Body != "" {
 		var ctHeader header.Header
 		ct, cs := mime.DetectContentType([]byte(e.Body))
+		if e.BodyType != "" {
+			ct = e.BodyType
+		}
+		content, cs, cte := e.encodeText(e.Body, cs)
 		if cs == "" {
 			ctHeader = header.NewContentType(ct, nil)
 		} else {
 			ctHeader = header.NewContentType(ct, header.NewMIMEParams("charset", cs))
 		}
 
-		body = mime.NewEntity([]header.Header{ctHeader}, e.Body)
+		hh := []header.Header{ctHeader}
+		if cte != "" {
+			hh = append(hh, header.NewContentTransferEncoding(cte))
+		}
+		body = mime.NewEntity(hh, content)
 	}
 
 	var inline, attachments []*mime.Entity
@@ -67,23 +152,48 @@ func (e *Email) Raw() string {
 		}
 	}
 
+	// text alternative, inline parts belong with the html they are
+	// referenced from: alternative > [text, related > [html, inline]]
+	if e.Text != "" {
+		_, cs := mime.DetectContentType([]byte(e.Text))
+		if cs == "" {
+			cs = "utf-8"
+		}
+		content, cs, cte := e.encodeText(e.Text, cs)
+		hh := []header.Header{header.NewContentType("text/plain", header.NewMIMEParams("charset", cs))}
+		if cte != "" {
+			hh = append(hh, header.NewContentTransferEncoding(cte))
+		}
+		text := mime.NewEntity(hh, content)
+
+		if body == nil {
+			body = text
+		} else {
+			if inline != nil {
+				body = mime.NewMultipartFrom(e.random(), "related", nil, append([]*mime.Entity{body}, inline...))
+				inline = nil
+			}
+			body = mime.NewMultipartFrom(e.random(), "alternative", nil, []*mime.Entity{text, body})
+		}
+	}
+
 	// everything empty
 	headers := e.getHeaders()
 	if body == nil && inline == nil && attachments == nil {
-		return mime.NewEntity(headers, "").String()
+		return mime.NewEntity(headers, "")
 	}
 
 	// single mime entity
 	headers = append([]header.Header{header.MIMEVersion{}}, headers...)
 	if body != nil && inline == nil && attachments == nil {
 		body.Headers = append(headers, body.Headers...)
-		return body.String()
+		return body
 	} else if body == nil && len(inline) == 1 && attachments == nil {
 		inline[0].Headers = append(headers, inline[0].Headers...)
-		return inline[0].String()
+		return inline[0]
 	} else if body == nil && inline == nil && len(attachments) == 1 {
 		attachments[0].Headers = append(headers, attachments[0].Headers...)
-		return attachments[0].String()
+		return attachments[0]
 	}
 
 	// multipart related
@@ -93,25 +203,25 @@ func (e *Email) Raw() string {
 		if body != nil {
 			parts = append([]*mime.Entity{body}, inline...)
 		}
-		related := mime.NewMultipartRelated(headers, parts)
-		return related.String()
+		related := mime.NewMultipartFrom(e.random(), "related", headers, parts)
+		return related
 	}
 
 	// multipart mixed
 	var mixed *mime.Entity
 	if attachments != nil {
 		if body == nil && inline == nil {
-			mixed = mime.NewMultipartMixed(headers, attachments)
+			mixed = mime.NewMultipartFrom(e.random(), "mixed", headers, attachments)
 		} else if body != nil && inline == nil {
 			parts = append([]*mime.Entity{body}, attachments...)
-			mixed = mime.NewMultipartMixed(headers, parts)
+			mixed = mime.NewMultipartFrom(e.random(), "mixed", headers, parts)
 		} else if body == nil && len(inline) == 1 {
 			parts = append(inline, attachments...)
-			mixed = mime.NewMultipartMixed(headers, parts)
+			mixed = mime.NewMultipartFrom(e.random(), "mixed", headers, parts)
 		}
 
 		if mixed != nil {
-			return mixed.String()
+			return mixed
 		}
 	}
 
@@ -120,30 +230,83 @@ func (e *Email) Raw() string {
 	if body != nil {
 		parts = append([]*mime.Entity{body}, inline...)
 	}
-	related := mime.NewMultipartRelated(nil, parts)
+	related := mime.NewMultipartFrom(e.random(), "related", nil, parts)
 	parts = append([]*mime.Entity{related}, attachments...)
-	mixed = mime.NewMultipartMixed(headers, parts)
-	return mixed.String()
+	mixed = mime.NewMultipartFrom(e.random(), "mixed", headers, parts)
+	return mixed
 }
 
 func (e *Email) getHeaders() []header.Header {
 	var hh []header.Header
+	if e.AutoDate && !e.hasHeader("Date") {
+		hh = append(hh, header.Date(e.now()))
+	}
 	if e.From != "" {
-		hh = append(hh, header.Address{Field: header.AddressFrom, Value: e.From})
+		hh = append(hh, header.Address{Field: header.AddressFrom, Value: e.From, Charset: e.Charset})
 	}
 	if e.To != "" {
-		hh = append(hh, header.Address{Field: header.AddressTo, Value: e.To})
+		hh = append(hh, header.Address{Field: header.AddressTo, Value: e.To, Charset: e.Charset})
 	}
 	if e.Cc != "" {
-		hh = append(hh, header.Address{Field: header.AddressCc, Value: e.Cc})
+		hh = append(hh, header.Address{Field: header.AddressCc, Value: e.Cc, Charset: e.Charset})
 	}
 	if e.Bcc != "" {
-		hh = append(hh, header.Address{Field: header.AddressBcc, Value: e.Bcc})
+		hh = append(hh, header.Address{Field: header.AddressBcc, Value: e.Bcc, Charset: e.Charset})
 	}
-	if e.Subject != "" {
+	if e.Subject != "" && e.Charset != "" {
+		hh = append(hh, header.CharsetSubject{Subject: header.Subject(e.Subject), Charset: e.Charset})
+	} else if e.Subject != "" {
 		hh = append(hh, header.Subject(e.Subject))
 	}
+	if e.List != nil {
+		hh = append(hh, e.List.Headers()...)
+	}
 	hh = append(hh, e.headers...)
 
 	return hh
 }
+
+// encodeText returns text s encoded in Charset, the charset name and
+// content transfer encoding. If Charset is unset or cannot represent s,
+// s is returned with cs. 8-bit text in a legacy charset is quoted-printable
+// encoded, as transports are not assumed to support 8BITMIME (RFC 6152)
+func (e *Email) encodeText(s string, cs string) (string, string, string) {
+	if e.Charset != "" && cs != "" {
+		if b, err := charset.Encode(e.Charset, s); err == nil {
+			s, cs = string(b), strings.ToLower(e.Charset)
+		}
+	}
+	if cs == "" || charset.IsUTF8(cs) || syntax.IsASCII(s) {
+		return s, cs, ""
+	}
+
+	sb := &strings.Builder{}
+	w := quotedprintable.NewWriter(sb)
+	w.Write([]byte(s)) // error always nil when using strings.Builder
+	w.Close()
+	return sb.String(), cs, "quoted-printable"
+}
+
+func (e *Email) hasHeader(name string) bool {
+	for _, h := range e.headers {
+		if h.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Email) now() time.Time {
+	if e.Clock != nil {
+		return e.Clock()
+	}
+	return e.Source.Now()
+}
+
+// random returns Source as a reader, or nil to use the default
+func (e *Email) random() io.Reader {
+	if e.Source == nil {
+		return nil
+	}
+	return e.Source
+}
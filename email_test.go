@@ -1,9 +1,13 @@
 package goemail_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	goemail "github.com/jimtsao/go-email"
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -64,7 +68,7 @@ func TestEmailComposition(t *testing.T) {
 		"Content-Disposition: attachment; filename=cat.png\r\n" +
 		"Content-Transfer-Encoding: base64\r\n" +
 		"\r\n" +
-		"iVBORw0K"
+		"iVBORw0KGgo="
 	want = wantHeader + wantAttachment
 	assert.Equal(t, want, m.Raw(), "1 attachment")
 
@@ -81,7 +85,7 @@ func TestEmailComposition(t *testing.T) {
 		"Content-Transfer-Encoding: base64\r\n" +
 		"Content-ID: <cat@png>\r\n" +
 		"\r\n" +
-		"iVBORw0K"
+		"iVBORw0KGgo="
 	want = wantHeader + wantInline
 	assert.Equal(t, want, m.Raw(), "1 inline")
 
@@ -200,3 +204,249 @@ func TestEmailComposition(t *testing.T) {
 		end
 	assert.Regexp(t, want, m.Raw(), "1 body, 1 inline, 1 attachment")
 }
+
+func TestEmailAutoDate(t *testing.T) {
+	m := goemail.New()
+	m.From = "a@a.com"
+	m.AutoDate = true
+	m.Clock = func() time.Time {
+		return time.Date(2000, time.January, 2, 12, 40, 20, 0, time.UTC)
+	}
+	want := "Date: Sun, 2 Jan 2000 12:40:20 +0000\r\n" +
+		"From: <a@a.com>\r\n" +
+		"\r\n"
+	assert.Equal(t, want, m.Raw(), "auto date")
+	assert.Empty(t, m.Validate(), "auto date")
+
+	// user supplied date takes precedence
+	m.AddHeader(header.Date(time.Date(1990, time.April, 3, 5, 30, 15, 0, time.UTC)))
+	want = "From: <a@a.com>\r\n" +
+		"Date: Tue, 3 Apr 1990 05:30:15 +0000\r\n" +
+		"\r\n"
+	assert.Equal(t, want, m.Raw(), "user date")
+}
+
+func TestEmailList(t *testing.T) {
+	m := goemail.New()
+	m.From = "news@a.com"
+	m.List = &goemail.MailingList{
+		ID:          "news.a.com",
+		Unsubscribe: []string{"mailto:leave@a.com", "https://a.com/leave?id=1"},
+		OneClick:    true,
+	}
+	want := "From: <news@a.com>\r\n" +
+		"List-ID: <news.a.com>\r\n" +
+		"List-Unsubscribe: <mailto:leave@a.com>, <https://a.com/leave?id=1>\r\n" +
+		"List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n" +
+		"\r\n"
+	assert.Equal(t, want, m.Raw())
+	m.AddHeader(header.Date(time.Now()))
+	assert.Empty(t, m.Validate())
+
+	// one-click requires https
+	m.List.Unsubscribe = m.List.Unsubscribe[:1]
+	assert.Len(t, m.Validate(), 1)
+}
+
+func TestEmailTextAlternative(t *testing.T) {
+	m := goemail.New()
+	m.From = "a@a.com"
+
+	// text only
+	m.Text = "hello world"
+	want := "MIME-Version: 1.0\r\n" +
+		"From: <a@a.com>\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		"hello world"
+	assert.Equal(t, want, m.Raw())
+
+	// alternative > [text, related > [html, inline]]
+	m.Body = "<b>hello world</b>"
+	m.Attachments = []*goemail.Attachment{{
+		Inline:    true,
+		Filename:  "cat.png",
+		ContentID: "<cat@png>",
+		Data:      []byte("\x89PNG\x0D\x0A\x1A\x0A"),
+	}}
+	e, err := mime.ReadEntity(strings.NewReader(m.Raw()))
+	assert.NoError(t, err)
+	mediatype, _ := e.ContentType()
+	assert.Equal(t, "multipart/alternative", mediatype)
+	assert.Len(t, e.Parts(), 2)
+	mediatype, _ = e.Parts()[0].ContentType()
+	assert.Equal(t, "text/plain", mediatype)
+	related := e.Parts()[1]
+	mediatype, _ = related.ContentType()
+	assert.Equal(t, "multipart/related", mediatype)
+	assert.Len(t, related.Parts(), 2)
+	assert.Equal(t, "<cat@png>", related.Parts()[1].Get("Content-ID"))
+
+	// html fragment sniffed as text unless labelled
+	m.Attachments = nil
+	m.Text = ""
+	m.Body = "Hi <b>there</b>"
+	assert.Contains(t, m.Raw(), "Content-Type: text/plain; charset=utf-8\r\n")
+	m.BodyType = "text/html"
+	assert.Contains(t, m.Raw(), "Content-Type: text/html; charset=utf-8\r\n")
+}
+
+// rawHeader is a third party header which does not sanitise its value
+type rawHeader string
+
+func (r rawHeader) Name() string    { return "X-Raw" }
+func (r rawHeader) Validate() error { return nil }
+func (r rawHeader) String() string  { return "X-Raw: " + string(r) + "\r\n" }
+
+func TestEmailHeaderInjection(t *testing.T) {
+	m := goemail.New()
+	m.AutoDate = true
+	m.From = "alice@a.com"
+	m.To = "bob@b.com\r\nBcc: eve@secret.com"
+	m.Subject = "hi\r\n\r\nbody"
+	m.AddHeader(rawHeader("a\r\nBcc: eve@secret.com"))
+	m.Body = "hello"
+
+	errs := m.Validate()
+	assert.Len(t, errs, 2)
+	for _, err := range errs {
+		assert.ErrorIs(t, err, header.ErrUnsafe)
+	}
+
+	raw := m.Raw()
+	assert.NotContains(t, raw, "\r\nBcc:")
+	assert.Contains(t, raw, "X-Raw: a Bcc: eve@secret.com\r\n")
+	head, body, _ := strings.Cut(raw, "\r\n\r\n")
+	assert.Contains(t, head, "Subject:")
+	assert.Equal(t, "hello", body)
+}
+
+func TestEmailBytes(t *testing.T) {
+	m := goemail.New()
+	m.AutoDate = true
+	m.Clock = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+	m.From = "alice@a.com"
+	m.To = "bob@b.com"
+	m.Body = "hello"
+	b, err := m.Bytes()
+	assert.NoError(t, err)
+	assert.Equal(t, m.Raw(), string(b))
+
+	// validation errors
+	m.To = "bob"
+	m.Subject = "hi\r\n"
+	_, err = m.Bytes()
+	var verr goemail.ValidationError
+	assert.ErrorAs(t, err, &verr)
+	assert.Len(t, verr, 2)
+
+	// body lines exceeding limit
+	m.To = "bob@b.com"
+	m.Subject = "hi"
+	m.Body = strings.Repeat("a", 1000)
+	_, err = m.Bytes()
+	assert.ErrorIs(t, err, mime.ErrLineTooLong)
+	assert.NotEmpty(t, m.Raw())
+}
+
+func TestEmailValidateWarnings(t *testing.T) {
+	m := goemail.New()
+	m.AutoDate = true
+	m.From = "alice@a.com"
+	m.To = "bob"
+	m.AddHeader(header.MessageID("<" + strings.Repeat("a", 80) + "@a.com>"))
+
+	errs := m.Validate()
+	assert.Len(t, errs, 2)
+	var herr *header.Error
+	assert.ErrorAs(t, errs[0], &herr)
+	assert.Equal(t, "To", herr.Field)
+	assert.Equal(t, header.SeverityError, herr.Severity)
+	assert.True(t, header.IsWarning(errs[1]))
+
+	// warnings do not prevent encoding
+	m.To = "bob@b.com"
+	_, err := m.Bytes()
+	assert.NoError(t, err)
+
+	// missing date is a warning
+	m = goemail.New()
+	m.From = "alice@a.com"
+	m.To = "bob@b.com"
+	errs = m.Validate()
+	if assert.Len(t, errs, 1) {
+		assert.True(t, header.IsWarning(errs[0]))
+	}
+	_, err = m.Bytes()
+	assert.NoError(t, err)
+
+	// obsolete address syntax is a warning, and output in modern form
+	m.To = "John Q. Public <@relay.com:john . public @ example . com>"
+	b, err := m.Bytes()
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "To: \"John Q. Public\" <john.public@example.com>\r\n")
+}
+
+func TestEmailSource(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	compose := func() string {
+		m := goemail.New()
+		m.Source = header.NewSeededSource(1, now)
+		m.AutoDate = true
+		m.From = "alice@a.com"
+		m.Text = "hello"
+		m.Body = `<p>hello</p><img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=">`
+		m.Attachments = append(m.Attachments, &goemail.Attachment{Filename: "a.txt", Data: []byte("a")})
+		assert.NoError(t, m.EmbedImages(nil))
+		return m.Raw()
+	}
+
+	raw := compose()
+	assert.Equal(t, raw, compose())
+	assert.Contains(t, raw, "Date: Mon, 1 Jan 2024 00:00:00 +0000\r\n")
+	assert.Contains(t, raw, "multipart/mixed")
+	assert.Contains(t, raw, "multipart/alternative")
+	assert.Contains(t, raw, "multipart/related")
+}
+
+func TestEmailCharset(t *testing.T) {
+	m := goemail.New()
+	m.AutoDate = true
+	m.Charset = "ISO-8859-1"
+	m.From = "Zoë <z@a.com>"
+	m.To = "b@b.com"
+	m.Subject = "café"
+	m.Text = "crème brûlée"
+	m.Body = "<p>中文</p>"
+	raw := m.Raw()
+	assert.Contains(t, raw, "From: =?iso-8859-1?q?Zo=EB?= <z@a.com>\r\n")
+	assert.Contains(t, raw, "Subject: =?iso-8859-1?q?caf=E9?=\r\n")
+	assert.Contains(t, raw, "Content-Type: text/plain; charset=iso-8859-1\r\n"+
+		"Content-Transfer-Encoding: quoted-printable\r\n\r\ncr=E8me br=FBl=E9e")
+	assert.Contains(t, raw, "Content-Type: text/html; charset=utf-8\r\n\r\n<p>中文</p>")
+
+	// round trip
+	e, err := mime.ReadEntity(strings.NewReader(raw))
+	if assert.NoError(t, err) {
+		text, err := e.Parts()[0].Text()
+		assert.NoError(t, err)
+		assert.Equal(t, "crème brûlée", text)
+	}
+	addrs, err := header.ParseAddressList(e.Get("From"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Zoë", addrs[0].Name)
+	}
+}
+
+func TestEmailDetectCharset(t *testing.T) {
+	m := goemail.New()
+	m.Text = "Le caf\xe9 est tr\xe8s bon"
+	m.Attachments = append(m.Attachments, &goemail.Attachment{
+		Filename: "notes.txt",
+		Data:     []byte("\xf0\xd2\xc9\xd7\xc5\xd4, \xcd\xc9\xd2"),
+	})
+	raw := m.Raw()
+	assert.Contains(t, raw, "Content-Type: text/plain; charset=windows-1252\r\n"+
+		"Content-Transfer-Encoding: quoted-printable\r\n\r\nLe caf=E9 est tr=E8s bon")
+	assert.Contains(t, raw, "Content-Type: text/plain; charset=koi8-r\r\n")
+}
@@ -0,0 +1,223 @@
+package goemail
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io/fs"
+	stdmime "mime"
+	"net/mail"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/jimtsao/go-email/mime"
+)
+
+// imgSrc matches the src attribute of img elements, capturing the attribute
+// prefix and the double quoted, single quoted or unquoted value
+var imgSrc = regexp.MustCompile(`(?i)(<img\b[^>]*?\ssrc\s*=\s*)(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+// EmbedImages scans an HTML Body for img elements referencing local files
+// or data: URIs, adding each image as an inline Attachment and rewriting
+// its src to a cid: reference. Raw then produces multipart/related.
+//
+// Relative and absolute file paths are opened from fsys, which may be nil
+// if only data: URIs are used. Remote (http, https) and existing cid:
+// sources are left unchanged. Images referenced more than once are
+// embedded once. Content-IDs are generated using the From domain and Source.
+// Body must be HTML, as given by BodyType or detected if BodyType is empty.
+// On error the email is left unchanged.
+//
+// usage:
+//
+//	e.Body = `<p>Hello</p><img src="images/logo.png">`
+//	err := e.EmbedImages(os.DirFS("templates"))
+func (e *Email) EmbedImages(fsys fs.FS) error {
+	ct := e.BodyType
+	if ct == "" {
+		ct, _ = mime.DetectContentType([]byte(e.Body))
+	}
+	if !strings.EqualFold(ct, "text/html") {
+		return fmt.Errorf("embed images: body is %s, not text/html", ct)
+	}
+
+	var domain string
+	if addr, err := mail.ParseAddress(e.From); err == nil {
+		_, domain, _ = strings.Cut(addr.Address, "@")
+	}
+
+	var err error
+	var unnamed int
+	var embedded []*Attachment
+	cids := map[string]string{}
+	body := imgSrc.ReplaceAllStringFunc(e.Body, func(match string) string {
+		if err != nil {
+			return match
+		}
+		m := imgSrc.FindStringSubmatch(match)
+		src := html.UnescapeString(m[2] + m[3] + m[4])
+		if !isEmbeddable(src) {
+			return match
+		}
+
+		key := imageKey(src)
+		cid, ok := cids[key]
+		if !ok {
+			var att *Attachment
+			if att, err = loadImage(fsys, src); err != nil {
+				err = fmt.Errorf("embed image %q: %w", truncate(src, 40), err)
+				return match
+			}
+			if att.Filename == "" {
+				unnamed++
+				att.Filename = fmt.Sprintf("image%d%s", unnamed, extension(att.ContentType))
+			}
+			att.Inline = true
+			att.ContentID = string(e.Source.MessageID(domain))
+			embedded = append(embedded, att)
+			cid = strings.Trim(att.ContentID, "<>")
+			cids[key] = cid
+		}
+
+		return m[1] + `"cid:` + cid + `"`
+	})
+
+	// email is left unchanged on error
+	if err != nil {
+		return err
+	}
+	e.Body = body
+	e.Attachments = append(e.Attachments, embedded...)
+	return nil
+}
+
+// isEmbeddable reports whether src refers to a local file or data: URI
+func isEmbeddable(src string) bool {
+	lower := strings.ToLower(strings.TrimSpace(src))
+	if lower == "" || strings.HasPrefix(lower, "data:") {
+		return lower != ""
+	}
+	for _, prefix := range []string{"cid:", "http:", "https:", "//"} {
+		if strings.HasPrefix(lower, prefix) {
+			return false
+		}
+	}
+	u, err := url.Parse(src)
+	return err == nil && (u.Scheme == "" || u.Scheme == "file")
+}
+
+// imageKey identifies the image referenced by src, so that
+// equivalent paths are embedded only once
+func imageKey(src string) string {
+	if strings.HasPrefix(strings.ToLower(src), "data:") {
+		return src
+	}
+	if u, err := url.Parse(src); err == nil {
+		return filePath(u)
+	}
+	return src
+}
+
+// filePath returns path to open in fs.FS, which does not permit leading slashes
+func filePath(u *url.URL) string {
+	return strings.TrimPrefix(path.Clean(u.Path), "/")
+}
+
+// loadImage reads image from data: URI or fsys
+func loadImage(fsys fs.FS, src string) (*Attachment, error) {
+	if strings.HasPrefix(strings.ToLower(src), "data:") {
+		ct, data, err := parseDataURI(src)
+		if err != nil {
+			return nil, err
+		}
+		return &Attachment{ContentType: ct, Data: data}, nil
+	}
+
+	if fsys == nil {
+		return nil, fmt.Errorf("no file system to read from")
+	}
+	u, err := url.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	name := filePath(u)
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	ct, _, _ := stdmime.ParseMediaType(stdmime.TypeByExtension(path.Ext(name)))
+	if ct == "" {
+		ct, _ = mime.DetectContentType(data)
+	}
+	if !strings.HasPrefix(ct, "image/") {
+		return nil, fmt.Errorf("not an image (%s)", ct)
+	}
+	return &Attachment{Filename: path.Base(name), ContentType: ct, Data: data}, nil
+}
+
+// parseDataURI decodes RFC 2397 data URI
+//
+// Syntax:
+//
+//	dataurl    := "data:" [ mediatype ] [ ";base64" ] "," data
+//	mediatype  := [ type "/" subtype ] *( ";" parameter )
+func parseDataURI(s string) (string, []byte, error) {
+	meta, data, found := strings.Cut(s[len("data:"):], ",")
+	if !found {
+		return "", nil, fmt.Errorf("malformed data URI")
+	}
+
+	isBase64 := false
+	if strings.HasSuffix(strings.ToLower(meta), ";base64") {
+		isBase64 = true
+		meta = meta[:len(meta)-len(";base64")]
+	}
+	ct := "text/plain"
+	if meta != "" {
+		mt, _, err := stdmime.ParseMediaType(meta)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid data URI media type: %w", err)
+		}
+		ct = mt
+	}
+	if !strings.HasPrefix(ct, "image/") {
+		return "", nil, fmt.Errorf("not an image (%s)", ct)
+	}
+
+	decoded, err := url.PathUnescape(data)
+	if err != nil {
+		return "", nil, err
+	}
+	if !isBase64 {
+		return ct, []byte(decoded), nil
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(decoded), ""))
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid data URI: %w", err)
+	}
+	return ct, b, nil
+}
+
+// extension returns preferred file extension for content type
+func extension(ct string) string {
+	switch ct {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/svg+xml":
+		return ".svg"
+	}
+	if exts, _ := stdmime.ExtensionsByType(ct); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n] + "..."
+	}
+	return s
+}
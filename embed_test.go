@@ -0,0 +1,110 @@
+package goemail_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	goemail "github.com/jimtsao/go-email"
+	"github.com/jimtsao/go-email/mime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbedImages(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08") // 25 bytes
+	fsys := fstest.MapFS{
+		"images/logo.png": {Data: png},
+		"notes.txt":       {Data: []byte("hello")},
+	}
+
+	m := goemail.New()
+	m.From = "alice@a.com"
+	m.Body = `<html><body>` +
+		`<img src="images/logo.png" alt="logo">` +
+		`<IMG alt='again' SRC='/images/logo.png'>` +
+		`<img src=data:image/gif;base64,R0lGODlhAQABAAAAACw=>` +
+		`<img src="https://a.com/remote.png">` +
+		`<img src="cid:existing@a.com">` +
+		`</body></html>`
+	assert.NoError(t, m.EmbedImages(fsys))
+
+	// attachments
+	assert.Len(t, m.Attachments, 2)
+	logo, gif := m.Attachments[0], m.Attachments[1]
+	assert.True(t, logo.Inline)
+	assert.Equal(t, "logo.png", logo.Filename)
+	assert.Equal(t, "image/png", logo.ContentType)
+	assert.Equal(t, png, logo.Data)
+	assert.Regexp(t, `^<[0-9a-z]+\.[0-9a-v]+@a\.com>$`, logo.ContentID)
+	assert.Equal(t, "image1.gif", gif.Filename)
+	assert.Equal(t, "image/gif", gif.ContentType)
+	assert.Equal(t, []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00,"), gif.Data)
+	assert.NotEqual(t, logo.ContentID, gif.ContentID)
+
+	// rewritten sources
+	logoCID := strings.Trim(logo.ContentID, "<>")
+	gifCID := strings.Trim(gif.ContentID, "<>")
+	want := `<html><body>` +
+		`<img src="cid:` + logoCID + `" alt="logo">` +
+		`<IMG alt='again' SRC="cid:` + logoCID + `">` +
+		`<img src="cid:` + gifCID + `">` +
+		`<img src="https://a.com/remote.png">` +
+		`<img src="cid:existing@a.com">` +
+		`</body></html>`
+	assert.Equal(t, want, m.Body)
+
+	// multipart/related
+	e, err := mime.ReadEntity(strings.NewReader(m.Raw()))
+	assert.NoError(t, err)
+	mediatype, _ := e.ContentType()
+	assert.Equal(t, "multipart/related", mediatype)
+	assert.Len(t, e.Parts(), 3)
+	assert.Equal(t, logo.ContentID, e.Parts()[1].Get("Content-ID"))
+	for i, want := range [][]byte{png, gif.Data} {
+		content, err := e.Parts()[i+1].Content()
+		assert.NoError(t, err)
+		assert.Equal(t, want, content)
+	}
+
+	// embedding again is a no-op
+	assert.NoError(t, m.EmbedImages(fsys))
+	assert.Len(t, m.Attachments, 2)
+}
+
+func TestEmbedImagesErrors(t *testing.T) {
+	fsys := fstest.MapFS{"notes.txt": {Data: []byte("hello")}}
+	for _, body := range []string{
+		`<html><img src="missing.png"></html>`,
+		`<html><img src="notes.txt"></html>`,
+		`<html><img src="data:text/plain,hello"></html>`,
+		`<html><img src="data:image/png;base64,!!"></html>`,
+		`<html><img src="data:image/png"></html>`,
+	} {
+		m := goemail.New()
+		m.Body = body
+		assert.Error(t, m.EmbedImages(fsys), body)
+		assert.Empty(t, m.Attachments, body)
+	}
+
+	// local file without file system
+	m := goemail.New()
+	m.Body = `<html><img src="logo.png"></html>`
+	assert.Error(t, m.EmbedImages(nil))
+
+	// plain text body is an error
+	m.Body = `see <img src="logo.png">`
+	assert.Error(t, m.EmbedImages(nil))
+	assert.Regexp(t, regexp.MustCompile(`logo\.png`), m.Body)
+	m.BodyType = "text/plain"
+	m.Body = `<html><img src="data:image/gif;base64,R0lGODlhAQABAAAAACw="></html>`
+	assert.Error(t, m.EmbedImages(nil))
+	assert.Empty(t, m.Attachments)
+
+	// html fragment labelled by BodyType
+	m.BodyType = "text/html"
+	m.Body = `<img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=">`
+	assert.NoError(t, m.EmbedImages(nil))
+	assert.Len(t, m.Attachments, 1)
+	assert.NotContains(t, m.Body, "data:")
+}
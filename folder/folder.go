@@ -2,12 +2,21 @@
 package folder
 
 import (
+	"errors"
 	"io"
 	"strings"
 )
 
 const maxLineLen = 78 // octets, excluding CRLF
 
+// hardLineLen is the limit no line may exceed, excluding CRLF,
+// as per RFC 5322 section 2.1.1
+const hardLineLen = 998
+
+// ErrLineTooLong is set as Folder.Err when tokens cannot be folded
+// to keep a line within 998 octets. The line is not written
+var ErrLineTooLong = errors.New("line exceeds 998 octets")
+
 var fwsToken = "\r\n "
 
 type Foldable interface {
@@ -17,11 +26,12 @@ type Foldable interface {
 }
 
 type Folder struct {
-	Err     error // io.Writer error
-	w       io.Writer
-	written int           // current line length written
-	acc     []interface{} // accumulator
-	closed  bool
+	Err      error // io.Writer error or ErrLineTooLong
+	w        io.Writer
+	written  int           // current line length written
+	acc      []interface{} // accumulator
+	closed   bool
+	unfolded strings.Builder
 }
 
 // New returns folder that supports header folding.
@@ -57,7 +67,12 @@ func (f *Folder) Write(tokens ...interface{}) {
 			if v != 0 {
 				f.acc = append(f.acc, v)
 			}
-		case string, Foldable:
+		case string:
+			f.unfolded.WriteString(v)
+			f.acc = append(f.acc, v)
+			f.fold()
+		case Foldable:
+			f.unfolded.WriteString(v.Value())
 			f.acc = append(f.acc, v)
 			f.fold()
 		}
@@ -76,6 +91,18 @@ func (f *Folder) flush() {
 		}
 	}
 
+	// lines of toWrite, the first continuing the current line
+	for i, line := range strings.Split(toWrite, "\r\n") {
+		n := len(line)
+		if i == 0 {
+			n += f.written
+		}
+		if n > hardLineLen {
+			f.Err = ErrLineTooLong
+			return
+		}
+	}
+
 	if _, f.Err = f.w.Write([]byte(toWrite)); f.Err != nil {
 		return
 	}
@@ -262,6 +289,12 @@ func (f *Folder) canFold(i int, checkLeft bool, checkRight bool) bool {
 	return lok && rok
 }
 
+// Unfolded returns all tokens written, without folding, for use
+// as a fallback if Err is set
+func (f *Folder) Unfolded() string {
+	return f.unfolded.String()
+}
+
 // Close flushes rest of buffered content and closes header
 func (f *Folder) Close() {
 	if f.closed || f.Err != nil {
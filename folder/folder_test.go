@@ -109,6 +109,16 @@ func (e EmptyFolder) Priority() int {
 	return e.priority
 }
 
+func TestLineTooLong(t *testing.T) {
+	sb := &strings.Builder{}
+	f := folder.New(sb)
+	f.Write("X-Long: ", 1, "foo", 1, s(1000))
+	f.Close()
+	assert.ErrorIs(t, f.Err, folder.ErrLineTooLong)
+	assert.NotContains(t, sb.String(), s(1000), "line not written")
+	assert.Equal(t, "X-Long: foo"+s(1000), f.Unfolded())
+}
+
 func TestWhitespaceCheck(t *testing.T) {
 	// WSP only before fold
 	desc := "WSP only before fold"
@@ -6,6 +6,8 @@ import (
 	"mime"
 	"strings"
 	"unicode/utf8"
+
+	"github.com/jimtsao/go-email/charset"
 )
 
 // NewWordEncodable represents a managed optionally encodable string that handles
@@ -20,7 +22,7 @@ type WordEncodable struct {
 }
 
 func (w WordEncodable) Value() string {
-	return w.encode(w.Decoded, w.MustEncode)
+	return w.encode(w.Decoded, w.MustEncode, "utf-8")
 }
 
 func (w WordEncodable) Priority() int {
@@ -28,6 +30,42 @@ func (w WordEncodable) Priority() int {
 }
 
 func (w WordEncodable) Fold(limit int) string {
+	return w.fold(limit, "utf-8")
+}
+
+// CharsetWordEncodable is a WordEncodable whose encoded words use
+// Charset, eg "iso-8859-1", if it can represent Decoded, otherwise utf-8
+//
+// usage:
+//
+//	we := CharsetWordEncodable{WordEncodable{"café", mime.QEncoding, false, 2}, "iso-8859-1"}
+type CharsetWordEncodable struct {
+	WordEncodable
+	Charset string
+}
+
+func (c CharsetWordEncodable) Value() string {
+	return c.encode(c.Decoded, c.MustEncode, c.charset())
+}
+
+func (c CharsetWordEncodable) Fold(limit int) string {
+	return c.fold(limit, c.charset())
+}
+
+// charset returns lower case Charset if it can represent Decoded, otherwise utf-8
+func (c CharsetWordEncodable) charset() string {
+	cs := strings.ToLower(c.Charset)
+	if cs == "" || charset.IsUTF8(cs) {
+		return "utf-8"
+	}
+	if _, err := charset.Encode(cs, c.Decoded); err != nil {
+		return "utf-8"
+	}
+	return cs
+}
+
+// fold folds Decoded as encoded words of charset cs
+func (w WordEncodable) fold(limit int, cs string) string {
 	sb := strings.Builder{}
 	remaining := w.Decoded
 
@@ -41,12 +79,12 @@ ITERATE:
 			if limit > 75 {
 				limit = 75
 			}
-			maxContentLen = limit - len("=?utf-8?q?") - len("?=")
+			maxContentLen = limit - len("=?"+cs+"?q?") - len("?=")
 		} else {
 			if limit > maxLineLen {
 				limit = maxLineLen
 			}
-			maxContentLen = limit - len("=?utf-8?b?") - len("?=")
+			maxContentLen = limit - len("=?"+cs+"?b?") - len("?=")
 			maxContentLen = base64.StdEncoding.DecodedLen(maxContentLen)
 		}
 
@@ -61,7 +99,15 @@ ITERATE:
 			// figure out encoded length of rune
 			var encLen int
 			b := remaining[i]
-			if w.Enc == mime.QEncoding {
+			if cs != "utf-8" {
+				// length of rune in charset
+				r, size := utf8.DecodeRuneInString(remaining[i:])
+				enc, _ := charset.Encode(cs, string(r))
+				runeLen, encLen = size, len(enc)
+				if w.Enc == mime.QEncoding {
+					encLen = qLen(enc)
+				}
+			} else if w.Enc == mime.QEncoding {
 				if b >= ' ' && b <= '~' && b != '=' && b != '?' && b != '_' {
 					runeLen, encLen = 1, 1
 				} else {
@@ -81,7 +127,7 @@ ITERATE:
 				}
 
 				// write folded part
-				split := w.encode(remaining[:i], true)
+				split := w.encode(remaining[:i], true, cs)
 				sb.WriteString(split + fwsToken)
 
 				// set remaining part
@@ -97,7 +143,7 @@ ITERATE:
 		}
 
 		// end of string reached, we are finished
-		remaining = w.encode(remaining, true)
+		remaining = w.encode(remaining, true, cs)
 		sb.WriteString(remaining)
 		break
 	}
@@ -105,7 +151,28 @@ ITERATE:
 	return sb.String()
 }
 
-func (w WordEncodable) encode(s string, force bool) string {
+func (w WordEncodable) encode(s string, force bool, cs string) string {
+	if cs != "utf-8" {
+		b, _ := charset.Encode(cs, s)
+		if !force {
+			return w.Enc.Encode(cs, string(b))
+		}
+		if w.Enc == mime.QEncoding {
+			sb := strings.Builder{}
+			for _, c := range b {
+				if c == ' ' {
+					sb.WriteByte('_')
+				} else if qLen([]byte{c}) == 1 {
+					sb.WriteByte(c)
+				} else {
+					fmt.Fprintf(&sb, "=%02X", c)
+				}
+			}
+			return fmt.Sprintf("=?%s?q?%s?=", cs, sb.String())
+		}
+		return fmt.Sprintf("=?%s?b?%s?=", cs, base64.StdEncoding.EncodeToString(b))
+	}
+
 	if !force {
 		return w.Enc.Encode("utf-8", w.Decoded)
 	}
@@ -122,3 +189,16 @@ func (w WordEncodable) encode(s string, force bool) string {
 	s = base64.StdEncoding.EncodeToString([]byte(s))
 	return fmt.Sprintf("=?utf-8?b?%s?=", s)
 }
+
+// qLen returns length of b in Q encoding (RFC 2047 section 4.2)
+func qLen(b []byte) int {
+	n := 0
+	for _, c := range b {
+		if c >= ' ' && c <= '~' && c != '=' && c != '?' && c != '_' {
+			n++
+		} else {
+			n += 3
+		}
+	}
+	return n
+}
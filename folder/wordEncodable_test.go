@@ -69,3 +69,33 @@ func TestWordEncodable(t *testing.T) {
 
 	testCases(t, "X-Header", tcs)
 }
+
+func TestWordEncodableCharset(t *testing.T) {
+	latin := folder.CharsetWordEncodable{folder.WordEncodable{"café", mime.QEncoding, true, 2}, "ISO-8859-1"}
+	sjis := folder.CharsetWordEncodable{folder.WordEncodable{"テスト", mime.BEncoding, true, 2}, "Shift_JIS"}
+	koi := folder.CharsetWordEncodable{folder.WordEncodable{"Привет мир", mime.QEncoding, false, 2}, "koi8-r"}
+	chinese := folder.CharsetWordEncodable{folder.WordEncodable{"中文", mime.QEncoding, true, 2}, "iso-8859-1"}
+
+	tcs := []testcase{
+		{desc: "q encoded no fold",
+			input: []interface{}{latin},
+			want:  "=?iso-8859-1?q?caf=E9?="},
+		{desc: "q encoded fold",
+			input: []interface{}{s(50), latin},
+			want:  s(50) + "=?iso-8859-1?q?c?=\r\n =?iso-8859-1?q?af=E9?="},
+		{desc: "b encoded no fold",
+			input: []interface{}{sjis},
+			want:  "=?shift_jis?b?g2WDWINn?="},
+		{desc: "b encoded fold (multibyte char)",
+			input: []interface{}{s(48), sjis},
+			want:  s(48) + "=?shift_jis?b?g2U=?=\r\n =?shift_jis?b?g1iDZw==?="},
+		{desc: "plain string (encode)",
+			input: []interface{}{koi},
+			want:  "=?koi8-r?q?=F0=D2=C9=D7=C5=D4_=CD=C9=D2?="},
+		{desc: "not representable in charset",
+			input: []interface{}{chinese},
+			want:  "=?utf-8?q?=E4=B8=AD=E6=96=87?="},
+	}
+
+	testCases(t, "X-Header", tcs)
+}
@@ -2,7 +2,11 @@ module github.com/jimtsao/go-email
 
 go 1.17
 
-require github.com/stretchr/testify v1.9.0
+require (
+	github.com/stretchr/testify v1.9.0
+	golang.org/x/net v0.17.0
+	golang.org/x/text v0.13.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
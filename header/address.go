@@ -6,7 +6,9 @@ import (
 	"net/mail"
 	"strings"
 
+	"github.com/jimtsao/go-email/charset"
 	"github.com/jimtsao/go-email/folder"
+	"github.com/jimtsao/go-email/syntax"
 )
 
 type AddressField string
@@ -18,6 +20,9 @@ const (
 	AddressTo      AddressField = "To"
 	AddressCc      AddressField = "Cc"
 	AddressBcc     AddressField = "Bcc"
+
+	// AddressDispositionNotificationTo requests a read receipt (RFC 8098)
+	AddressDispositionNotificationTo AddressField = "Disposition-Notification-To"
 )
 
 // Address represents an Originator or Destination Address header field
@@ -30,6 +35,13 @@ const (
 //
 // note: Domain literals and groups are not supported
 //
+// Addresses using the obsolete syntax of RFC 5322 section 4.4, such as
+// source routes or white space around dots, are output in modern form,
+// see ParseAddressList. Validate reports them as a warning
+//
+// Internationalised domain names are output in their A-label (punycode)
+// form, see DomainToASCII
+//
 // Syntax:
 //
 //	from            =   "From:" mailbox-list CRLF
@@ -38,6 +50,7 @@ const (
 //	to              =   "To:" address-list CRLF
 //	cc              =   "Cc:" address-list CRLF
 //	bcc             =   "Bcc:" [address-list / CFWS] CRLF
+//	mdn-request     =   "Disposition-Notification-To:" mailbox *("," mailbox)
 //	address         =   mailbox / group
 //	addr-spec       =   local-part "@" domain
 //	local-part      =   dot-atom / quoted-string
@@ -60,8 +73,9 @@ const (
 //	qcontent        =   qtext / quoted-pair
 //	qtext           =   %d32 / %d33 / %d35-91 / %d93-126
 type Address struct {
-	Field AddressField
-	Value string
+	Field   AddressField
+	Value   string
+	Charset string // of encoded-word display names if it can represent them, defaults to utf-8
 }
 
 func (a Address) Name() string {
@@ -69,31 +83,52 @@ func (a Address) Name() string {
 }
 
 func (a Address) Validate() error {
-	// parse addresses
-	addrs, err := mail.ParseAddressList(a.Value)
+	if err := checkUnsafe(a.Name(), "field-body", a.Value); err != nil {
+		return err
+	}
+
+	// parse addresses, accepting obsolete syntax as String does
+	addrs, err := ParseAddressList(a.Value)
 	if err != nil {
-		return fmt.Errorf("%s: %w", a.Field, err)
+		return newError(a.Name(), a.Value, "address-list", "RFC 5322 section 3.4",
+			"%w", err).at(syntaxOffset(err))
 	}
 
 	// check sender only 1 single address
 	if a.Field == AddressSender && len(addrs) > 1 {
-		return fmt.Errorf("%s: %s", a.Field, "must not contain more than 1 address")
+		return newError(a.Name(), a.Value, "mailbox", "RFC 5322 section 3.6.2",
+			"must not contain more than 1 address")
 	}
 
 	// smtp restriction: local-part max 64 octets, domain max 255 octets
+	// domain length applies to the A-label form transmitted over the wire
 	for _, addr := range addrs {
 		local, domain, _ := strings.Cut(addr.Address, "@")
+		ascii, err := DomainToASCII(domain)
+		if err != nil {
+			return newError(a.Name(), a.Value, "domain", "RFC 5890 section 2.3.2.1",
+				"invalid internationalised domain (%q): %w", domain, err).at(strings.Index(a.Value, domain))
+		}
 		if len(local) > 64 {
-			return fmt.Errorf("%s: address part exceeds max length 64 bytes (%q)", a.Name(), local)
-		} else if len(domain) > 255 {
-			return fmt.Errorf("%s: address part exceeds max length 255 bytes (%q)", a.Name(), domain)
+			return newError(a.Name(), a.Value, "local-part", "RFC 5321 section 4.5.3.1.1",
+				"address part exceeds max length 64 bytes (%q)", local).at(strings.Index(a.Value, local))
+		} else if len(ascii) > 255 {
+			return newError(a.Name(), a.Value, "domain", "RFC 5321 section 4.5.3.1.2",
+				"address part exceeds max length 255 bytes (%q)", ascii).at(strings.Index(a.Value, domain))
 		}
 	}
 
+	// obsolete syntax is output in modern form, but must not be generated
+	if _, err := (syntax.Parser{}).AddressList(a.Value); err != nil {
+		return newError(a.Name(), a.Value, "obs-addr-list", "RFC 5322 section 4.4",
+			"%w", err).at(syntaxOffset(err)).warn()
+	}
+
 	return nil
 }
 
 func (a Address) String() string {
+	a.Value = Sanitise(a.Value)
 	sb := &strings.Builder{}
 	f := folder.New(sb)
 	f.Write(a.Name() + ": ")
@@ -102,14 +137,14 @@ func (a Address) String() string {
 	switch a.Field {
 	case AddressSender:
 		// single address
-		if addr, err := mail.ParseAddress(a.Value); err != nil {
+		if addrs, err := ParseAddressList(a.Value); err != nil || len(addrs) != 1 || toASCII(addrs) != nil {
 			fallback = a.Value
 		} else {
-			a.writeAddress(addr, f)
+			a.writeAddress(addrs[0], f)
 		}
-	case AddressFrom, AddressReplyTo, AddressTo, AddressCc, AddressBcc:
+	case AddressFrom, AddressReplyTo, AddressTo, AddressCc, AddressBcc, AddressDispositionNotificationTo:
 		// multiple address
-		if addrs, err := mail.ParseAddressList(a.Value); err != nil {
+		if addrs, err := ParseAddressList(a.Value); err != nil || len(addrs) == 0 || toASCII(addrs) != nil {
 			fallback = a.Value
 		} else {
 			for i := 0; i < len(addrs); i++ {
@@ -123,12 +158,11 @@ func (a Address) String() string {
 		fallback = a.Value
 	}
 
-	if fallback != "" || f.Err != nil {
+	if fallback != "" {
 		return fmt.Sprintf("%s: %s\r\n", a.Field, fallback)
 	}
 
-	f.Close()
-	return sb.String()
+	return closeFolder(f, sb)
 }
 
 func (a Address) writeAddress(addr *mail.Address, f *folder.Folder) {
@@ -166,11 +200,13 @@ func (a Address) writeAddress(addr *mail.Address, f *folder.Folder) {
 	} else if e != "" {
 		// format: [3:encoded-word][2][space]angle-addr[1]
 		f.Write(
-			folder.WordEncodable{
-				Decoded:      addr.Name,
-				Enc:          mime.QEncoding,
-				MustEncode:   true,
-				FoldPriority: 3},
+			folder.CharsetWordEncodable{
+				WordEncodable: folder.WordEncodable{
+					Decoded:      addr.Name,
+					Enc:          mime.QEncoding,
+					MustEncode:   true,
+					FoldPriority: 3},
+				Charset: a.Charset},
 			folder.FWS(2), d, 1)
 	} else {
 		// angle-addr: [CFWS] "<" local @ domain ">" [CFWS]
@@ -178,3 +214,45 @@ func (a Address) writeAddress(addr *mail.Address, f *folder.Folder) {
 		f.Write(1, d, 1)
 	}
 }
+
+// toASCII converts internationalised domains of addrs to A-labels, so
+// that transports without SMTPUTF8 support still receive valid addresses.
+// An error is returned if any domain is not a valid IDN, see Validate
+func toASCII(addrs []*mail.Address) error {
+	for _, addr := range addrs {
+		i := strings.LastIndex(addr.Address, "@")
+		if i == -1 {
+			continue
+		}
+		domain, err := DomainToASCII(addr.Address[i+1:])
+		if err != nil {
+			return err
+		}
+		addr.Address = addr.Address[:i+1] + domain
+	}
+	return nil
+}
+
+// ParseAddressList parses an address list leniently, accepting the
+// obsolete syntax of RFC 5322 section 4.4 found in older mail, and
+// returns each mailbox in modern form. Mailboxes of groups are included.
+// Display names containing encoded-words are decoded
+//
+// eg, "John Q. Public <@relay.com:john . public @ example . com>"
+// returns name "John Q. Public" and address "john.public@example.com"
+func ParseAddressList(s string) ([]*mail.Address, error) {
+	mbs, err := syntax.Parser{Lenient: true}.AddressList(s)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]*mail.Address, 0, len(mbs))
+	for _, mb := range mbs {
+		name, err := charset.DecodeHeader(mb.Name)
+		if err != nil {
+			name = mb.Name
+		}
+		addrs = append(addrs, &mail.Address{Name: name, Address: mb.Addr})
+	}
+	return addrs, nil
+}
@@ -27,6 +27,72 @@ func TestAddressValidate(t *testing.T) {
 	addr.Value = fmt.Sprintf("a@%s.com", strings.Repeat("b", 256))
 	err = addr.Validate()
 	assert.Error(t, err)
+
+	// internationalised domain within 255 octets as U-label but not as A-label
+	domain := strings.TrimSuffix(strings.Repeat("ü.", 85), ".")
+	ascii, err := header.DomainToASCII(domain)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, len(domain), 255)
+	assert.Greater(t, len(ascii), 255)
+	addr.Value = fmt.Sprintf("a@%s", domain)
+	err = addr.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "max length 255")
+	}
+
+	// invalid internationalised domain
+	addr.Value = "a@xn--ü.com"
+	err = addr.Validate()
+	assert.Error(t, err)
+
+	// internationalised domain
+	addr.Value = "a@bücher.example"
+	err = addr.Validate()
+	assert.NoError(t, err)
+}
+
+func TestAddressIDNA(t *testing.T) {
+	for _, c := range []struct {
+		input string
+		want  string
+	}{
+		{"joerg@bücher.example", "<joerg@xn--bcher-kva.example>"},
+		{"Jörg <jörg@bücher.example>", "=?utf-8?q?J=C3=B6rg?= <jörg@xn--bcher-kva.example>"},
+		{"yamada@例え.テスト, a@b.com", "<yamada@xn--r8jz45g.xn--zckzah>,<a@b.com>"},
+	} {
+		a := header.Address{Field: header.AddressTo, Value: c.input}
+		assert.NoError(t, a.Validate(), c.input)
+		assert.Equal(t, fmt.Sprintf("To: %s\r\n", c.want), a.String(), c.input)
+	}
+
+	// invalid domain is output unchanged, as are unparsable addresses
+	a := header.Address{Field: header.AddressTo, Value: "a@b.com, c@xn--ü.com"}
+	assert.Error(t, a.Validate())
+	assert.Equal(t, "To: a@b.com, c@xn--ü.com\r\n", a.String())
+}
+
+func TestAddressObsolete(t *testing.T) {
+	for _, c := range []struct {
+		field header.AddressField
+		input string
+		want  string
+	}{
+		{header.AddressFrom, "John Q. Public <@relay.com:john . public @ example . com>", `"John Q. Public" <john.public@example.com>`},
+		{header.AddressSender, "jqp(comment) @ example.com", "<jqp@example.com>"},
+		{header.AddressTo, ", a@b.com,,=?utf-8?q?Zo=C3=AB?= <c@d.com>,", "<a@b.com>,=?utf-8?q?Zo=C3=AB?= <c@d.com>"},
+	} {
+		a := header.Address{Field: c.field, Value: c.input}
+		assert.Equal(t, fmt.Sprintf("%s: %s\r\n", c.field, c.want), a.String(), c.input)
+		if err := a.Validate(); err != nil {
+			assert.True(t, header.IsWarning(err), c.input)
+		}
+	}
+
+	addrs, err := header.ParseAddressList("=?utf-8?q?Zo=C3=AB?= <z . b @ c.com>")
+	if assert.NoError(t, err) && assert.Len(t, addrs, 1) {
+		assert.Equal(t, "Zoë", addrs[0].Name)
+		assert.Equal(t, "z.b@c.com", addrs[0].Address)
+	}
 }
 
 func TestAddressNoFold(t *testing.T) {
@@ -1,7 +1,6 @@
 package header
 
 import (
-	"fmt"
 	"mime"
 	"strings"
 
@@ -25,6 +24,7 @@ type CustomHeader struct {
 	FieldName     string
 	Value         string
 	WordEncodable bool
+	Charset       string // of encoded-words if it can represent Value, defaults to utf-8
 }
 
 // Name returns header name
@@ -33,16 +33,26 @@ func (u CustomHeader) Name() string {
 }
 
 func (u CustomHeader) Validate() error {
-	if strings.Contains(u.Value, ":") {
-		return fmt.Errorf("%s must not contain a colon", u.FieldName)
+	if err := checkUnsafe(u.FieldName, "field-name", u.FieldName); err != nil {
+		return err
+	}
+	if err := checkUnsafe(u.FieldName, "field-body", u.Value); err != nil {
+		return err
+	}
+
+	if i := strings.Index(u.Value, ":"); i != -1 {
+		return newError(u.FieldName, u.Value, "unstructured", "RFC 5322 section 3.6.8",
+			"must not contain a colon").at(i)
 	}
 
 	if u.WordEncodable && !syntax.IsWordEncodable(u.Value) {
-		return fmt.Errorf("%s must contain only printable or white space characters", u.FieldName)
+		return newError(u.FieldName, u.Value, "encoded-word", "RFC 2047 section 5",
+			"must contain only printable or white space characters").at(indexInvalid(u.Value, syntax.IsWordEncodable))
 	}
 
 	if !syntax.IsFtext(u.FieldName) {
-		return fmt.Errorf("%s invalid syntax", u.FieldName)
+		return newError(u.FieldName, u.FieldName, "field-name", "RFC 5322 section 3.6.8",
+			"invalid syntax").at(indexInvalid(u.FieldName, syntax.IsFtext))
 	}
 	return nil
 }
@@ -51,17 +61,17 @@ func (u CustomHeader) String() string {
 	// format: header-name:[1][space][2:word-encodable]
 	sb := &strings.Builder{}
 	f := folder.New(sb)
-	f.Write(u.Name()+":", folder.FWS(1))
+	f.Write(Sanitise(u.Name())+":", folder.FWS(1))
+	value := Sanitise(u.Value)
 	if u.WordEncodable {
 		we := folder.WordEncodable{
-			Decoded:      u.Value,
+			Decoded:      value,
 			Enc:          mime.QEncoding,
 			MustEncode:   false,
 			FoldPriority: 2}
-		f.Write(we)
+		f.Write(folder.CharsetWordEncodable{WordEncodable: we, Charset: u.Charset})
 	} else {
-		f.Write(u.Value)
+		f.Write(value)
 	}
-	f.Close()
-	return sb.String()
+	return closeFolder(f, sb)
 }
@@ -1,7 +1,10 @@
 package header
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -33,7 +36,35 @@ func (d Date) Name() string {
 	return "Date"
 }
 
+// Validate checks date can be represented in date-time syntax:
+//
+//	year must be 1900 or later, with no more than 4 digits
+//	zone offset must be whole minutes, no greater than 99 hours 59 minutes
 func (d Date) Validate() error {
+	t := time.Time(d)
+	if t.IsZero() {
+		return newError(d.Name(), "", "date-time", "RFC 5322 section 3.6.1", "must not be zero")
+	}
+
+	v := t.Format(TimeRFC5322)
+	if y := t.Year(); y < 1900 || y > 9999 {
+		return newError(d.Name(), v, "year", "RFC 5322 section 3.3",
+			"year must be between 1900 and 9999, has %d", y)
+	}
+
+	_, offset := t.Zone()
+	if offset%60 != 0 {
+		return newError(d.Name(), v, "zone", "RFC 5322 section 3.3",
+			"zone offset must be whole minutes, has %ds", offset)
+	}
+	if offset < 0 {
+		offset = -offset
+	}
+	if offset > 99*3600+59*60 {
+		return newError(d.Name(), v, "zone", "RFC 5322 section 3.3",
+			"zone offset must not exceed 9959")
+	}
+
 	return nil
 }
 
@@ -41,3 +72,205 @@ func (d Date) String() string {
 	dt := time.Time(d).Format(TimeRFC5322)
 	return fmt.Sprintf("%s: %s\r\n", d.Name(), dt)
 }
+
+// obs-zone values, military zones other than "Z" are
+// treated as -0000 as recommended by RFC 5322 section 4.3
+var obsZones = map[string]int{
+	"UT": 0, "GMT": 0, "Z": 0,
+	"EST": -5, "EDT": -4,
+	"CST": -6, "CDT": -5,
+	"MST": -7, "MDT": -6,
+	"PST": -8, "PDT": -7,
+}
+
+var days = map[string]bool{
+	"mon": true, "tue": true, "wed": true, "thu": true,
+	"fri": true, "sat": true, "sun": true,
+}
+
+var months = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March,
+	"apr": time.April, "may": time.May, "jun": time.June,
+	"jul": time.July, "aug": time.August, "sep": time.September,
+	"oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+// ParseDate parses a date-time, accepting obsolete syntax:
+//
+//	obs-day-of-week =   [CFWS] day-name [CFWS]
+//	obs-day         =   [CFWS] 1*2DIGIT [CFWS]
+//	obs-year        =   [CFWS] 2*DIGIT [CFWS]
+//	obs-hour        =   [CFWS] 2DIGIT [CFWS]
+//	obs-zone        =   "UT" / "GMT" /
+//	                    "EST" / "EDT" / "CST" / "CDT" /
+//	                    "MST" / "MDT" / "PST" / "PDT" /
+//	                    %d65-73 / %d75-90 / %d97-105 / %d107-122
+//
+// Comments are ignored and day-of-week is optional. Two digit years
+// are interpreted as 2000-2049 for 00-49 and 1950-1999 for 50-99,
+// three digit years have 1900 added to them (RFC 5322 section 4.3)
+func ParseDate(s string) (time.Time, error) {
+	s, err := stripComments(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date: %w", err)
+	}
+
+	// tokenise, day-of-week comma may be attached to day-name or stand alone
+	fields := strings.Fields(strings.ReplaceAll(s, ",", " , "))
+	if len(fields) > 0 && len(fields[0]) >= 3 && !isDigits(fields[0]) {
+		if !days[strings.ToLower(fields[0])] {
+			return time.Time{}, fmt.Errorf("date: invalid day-of-week %q", fields[0])
+		}
+		fields = fields[1:]
+		if len(fields) > 0 && fields[0] == "," {
+			fields = fields[1:]
+		}
+	}
+	if len(fields) < 4 {
+		return time.Time{}, errors.New("date: missing date or time")
+	}
+
+	// date
+	day, err := strconv.Atoi(fields[0])
+	if err != nil || len(fields[0]) > 2 {
+		return time.Time{}, fmt.Errorf("date: invalid day %q", fields[0])
+	}
+	month, ok := months[strings.ToLower(fields[1])]
+	if !ok {
+		return time.Time{}, fmt.Errorf("date: invalid month %q", fields[1])
+	}
+	year, err := strconv.Atoi(fields[2])
+	if err != nil || len(fields[2]) < 2 || !isDigits(fields[2]) {
+		return time.Time{}, fmt.Errorf("date: invalid year %q", fields[2])
+	}
+	switch len(fields[2]) {
+	case 2:
+		if year < 50 {
+			year += 2000
+		} else {
+			year += 1900
+		}
+	case 3:
+		year += 1900
+	}
+
+	// time-of-day, obs-hour etc. may contain CFWS around ":"
+	rest := strings.Join(fields[3:], "")
+	var hms []int
+	for len(hms) < 3 {
+		n := 0
+		for n < len(rest) && n < 2 && isDigits(rest[n:n+1]) {
+			n++
+		}
+		if n != 2 {
+			break
+		}
+		v, _ := strconv.Atoi(rest[:n])
+		hms = append(hms, v)
+		rest = rest[n:]
+		if !strings.HasPrefix(rest, ":") {
+			break
+		}
+		rest = rest[1:]
+	}
+	if len(hms) < 2 {
+		return time.Time{}, errors.New("date: invalid time-of-day")
+	}
+	if len(hms) == 2 {
+		hms = append(hms, 0)
+	}
+	if hms[0] > 23 || hms[1] > 59 || hms[2] > 60 {
+		return time.Time{}, fmt.Errorf("date: invalid time-of-day %02d:%02d:%02d", hms[0], hms[1], hms[2])
+	}
+
+	// zone
+	loc, err := parseZone(rest)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t := time.Date(year, month, day, hms[0], hms[1], hms[2], 0, loc)
+	if t.Day() != day {
+		return time.Time{}, fmt.Errorf("date: invalid day %d for %s %d", day, month, year)
+	}
+	return t, nil
+}
+
+func parseZone(z string) (*time.Location, error) {
+	if z == "" {
+		return nil, errors.New("date: missing zone")
+	}
+
+	// numeric zone, ignoring any trailing obs-zone some
+	// clients append without a comment, eg "+0000 GMT"
+	if (z[0] == '+' || z[0] == '-') && len(z) >= 5 && isDigits(z[1:5]) {
+		if trail := strings.ToUpper(z[5:]); trail != "" {
+			if _, ok := obsZones[trail]; !ok {
+				return nil, fmt.Errorf("date: invalid zone %q", z)
+			}
+		}
+		z = z[:5]
+		hh, _ := strconv.Atoi(z[1:3])
+		mm, _ := strconv.Atoi(z[3:])
+		if mm > 59 {
+			return nil, fmt.Errorf("date: invalid zone %q", z)
+		}
+		offset := hh*3600 + mm*60
+		if z[0] == '-' {
+			offset = -offset
+		}
+		return time.FixedZone("", offset), nil
+	}
+
+	// obs-zone
+	upper := strings.ToUpper(z)
+	if h, ok := obsZones[upper]; ok {
+		return time.FixedZone(upper, h*3600), nil
+	}
+	if len(z) == 1 && 'A' <= upper[0] && upper[0] <= 'Z' && upper[0] != 'J' {
+		return time.FixedZone("", 0), nil
+	}
+
+	return nil, fmt.Errorf("date: invalid zone %q", z)
+}
+
+// stripComments replaces comments, which may be nested
+// and contain quoted-pairs, with a single space
+func stripComments(s string) (string, error) {
+	sb := strings.Builder{}
+	depth := 0
+	escaped := false
+	for _, r := range s {
+		switch {
+		case depth > 0 && escaped:
+			escaped = false
+		case depth > 0 && r == '\\':
+			escaped = true
+		case r == '(':
+			depth++
+		case r == ')':
+			if depth == 0 {
+				return "", errors.New("unbalanced comment")
+			}
+			depth--
+			if depth == 0 {
+				sb.WriteRune(' ')
+			}
+		case depth == 0:
+			sb.WriteRune(r)
+		}
+	}
+	if depth != 0 {
+		return "", errors.New("unterminated comment")
+	}
+	return sb.String(), nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -30,3 +30,61 @@ func TestDate(t *testing.T) {
 		assert.Equal(t, want, got)
 	}
 }
+
+func TestDateValidate(t *testing.T) {
+	for _, c := range []struct {
+		desc  string
+		input time.Time
+	}{
+		{"zero", time.Time{}},
+		{"year before 1900", time.Date(1899, time.December, 31, 0, 0, 0, 0, time.UTC)},
+		{"year after 9999", time.Date(10000, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"zone not whole minutes", time.Date(2000, time.January, 1, 0, 0, 0, 0, time.FixedZone("", 30))},
+		{"zone exceeds 9959", time.Date(2000, time.January, 1, 0, 0, 0, 0, time.FixedZone("", 100*3600))},
+	} {
+		assert.Error(t, header.Date(c.input).Validate(), c.desc)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	est := time.FixedZone("EST", -5*3600)
+	pdt := time.FixedZone("PDT", -7*3600)
+	for _, c := range []struct {
+		input string
+		want  time.Time
+	}{
+		{"Tue, 3 Apr 1990 05:30:15 +1000", time.Date(1990, time.April, 3, 5, 30, 15, 0, time.FixedZone("", 10*3600))},
+		{"3 Apr 1990 05:30:15 +1000", time.Date(1990, time.April, 3, 5, 30, 15, 0, time.FixedZone("", 10*3600))},
+		{"Tue , 03 apr 1990 05:30 -0000", time.Date(1990, time.April, 3, 5, 30, 0, 0, time.UTC)},
+		{"Tue, 3 Apr 90 05:30:15 EST", time.Date(1990, time.April, 3, 5, 30, 15, 0, est)},
+		{"Sun, 2 Jan 00 12:40:20 GMT", time.Date(2000, time.January, 2, 12, 40, 20, 0, time.UTC)},
+		{"Sun, 2 Jan 100 12:40:20 UT", time.Date(2000, time.January, 2, 12, 40, 20, 0, time.UTC)},
+		{"Mon, 10 Sep 2012 02:04:00 PDT", time.Date(2012, time.September, 10, 2, 4, 0, 0, pdt)},
+		{"Mon, 10 Sep 2012 02:04:00 A", time.Date(2012, time.September, 10, 2, 4, 0, 0, time.UTC)},
+		{"Mon (comment (nested)), 10 Sep 2012 02 : 04 : 00 +0000 (UTC)", time.Date(2012, time.September, 10, 2, 4, 0, 0, time.UTC)},
+		{"Mon, 10 Sep 2012 02:04:00 +0000 GMT", time.Date(2012, time.September, 10, 2, 4, 0, 0, time.UTC)},
+	} {
+		got, err := header.ParseDate(c.input)
+		assert.NoError(t, err, c.input)
+		assert.True(t, c.want.Equal(got), "%s: want %s, got %s", c.input, c.want, got)
+	}
+
+	for _, input := range []string{
+		"",
+		"Tue, 3 Apr 1990",
+		"Tue, 3 Foo 1990 05:30:15 +1000",
+		"Tue, 31 Apr 1990 05:30:15 +1000",
+		"Tue, 3 Apr 1990 25:30:15 +1000",
+		"Tue, 3 Apr 1990 05:30:15",
+		"Tue, 3 Apr 1990 05:30:15 +1060",
+		"Tue, 3 Apr 1990 05:30:15 XYZ",
+		"Tue, 3 Apr 1990 05:30:15 +0000xyz",
+		"Tue, 3 Apr 1990 05:30:15 +0000 GMTX",
+		"Foo, 3 Apr 1990 05:30:15 +1000",
+		"Tuesday, 3 Apr 1990 05:30:15 +1000",
+		"Tue, 3 Apr 1990 05:30:15 +1000 (unterminated",
+	} {
+		_, err := header.ParseDate(input)
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,34 @@
+package header
+
+import (
+	"strings"
+
+	"github.com/jimtsao/go-email/syntax"
+	"golang.org/x/net/idna"
+)
+
+// DomainToASCII converts an internationalised domain name into
+// its ASCII compatible form, where each label containing non us-ascii
+// characters (U-label) is converted to its punycode form (A-label)
+//
+// eg, "bücher.example" becomes "xn--bcher-kva.example"
+//
+// domains consisting only of us-ascii are returned unchanged, since
+// RFC 5322 permits atext in a domain which is stricter in IDNA
+func DomainToASCII(domain string) (string, error) {
+	if syntax.IsASCII(domain) {
+		return domain, nil
+	}
+	return idna.Lookup.ToASCII(domain)
+}
+
+// DomainToUnicode converts each A-label of a domain, i.e. labels
+// beginning with "xn--", back into its U-label form for display
+//
+// eg, "xn--bcher-kva.example" becomes "bücher.example"
+func DomainToUnicode(domain string) (string, error) {
+	if !strings.Contains(strings.ToLower(domain), "xn--") {
+		return domain, nil
+	}
+	return idna.Display.ToUnicode(domain)
+}
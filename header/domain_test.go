@@ -0,0 +1,43 @@
+package header_test
+
+import (
+	"testing"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainToASCII(t *testing.T) {
+	for _, c := range []struct {
+		input string
+		want  string
+	}{
+		{"example.com", "example.com"},
+		{"under_score.com", "under_score.com"},
+		{"bücher.example", "xn--bcher-kva.example"},
+		{"例え.テスト", "xn--r8jz45g.xn--zckzah"},
+		{"BÜCHER.example", "xn--bcher-kva.example"},
+	} {
+		got, err := header.DomainToASCII(c.input)
+		assert.NoError(t, err, c.input)
+		assert.Equal(t, c.want, got, c.input)
+	}
+
+	_, err := header.DomainToASCII("bü--cher.example‍")
+	assert.Error(t, err, "invalid joiner")
+}
+
+func TestDomainToUnicode(t *testing.T) {
+	for _, c := range []struct {
+		input string
+		want  string
+	}{
+		{"example.com", "example.com"},
+		{"xn--bcher-kva.example", "bücher.example"},
+		{"xn--r8jz45g.xn--zckzah", "例え.テスト"},
+	} {
+		got, err := header.DomainToUnicode(c.input)
+		assert.NoError(t, err, c.input)
+		assert.Equal(t, c.want, got, c.input)
+	}
+}
@@ -0,0 +1,105 @@
+package header
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jimtsao/go-email/syntax"
+)
+
+// Severity indicates whether a validation Error should prevent sending
+type Severity int
+
+const (
+	// SeverityError violates a requirement (MUST) of the relevant RFC,
+	// the message may be rejected or misinterpreted by recipients
+	SeverityError Severity = iota
+	// SeverityWarning violates a recommendation (SHOULD) of the relevant
+	// RFC, the message is valid but may not be handled well by all software
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Error is returned by Validate, describing where and why a header
+// field is invalid. Use errors.As to retrieve it from an error:
+//
+//	var herr *header.Error
+//	if errors.As(err, &herr) {
+//		highlight(herr.Field, herr.Offset)
+//	}
+type Error struct {
+	Field    string   // header field name, eg "From"
+	Value    string   // offending field body or part of it
+	Offset   int      // byte offset into Value of the violation, or -1 if unknown
+	Rule     string   // ABNF rule or constraint violated, eg "addr-spec"
+	RFC      string   // section defining Rule, eg "RFC 5322 section 3.4.1"
+	Severity Severity // SeverityError unless stated otherwise
+	Err      error    // description of violation
+}
+
+// Error formats as "Field: description"
+func (e *Error) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsWarning reports whether err is an Error of SeverityWarning.
+// Any other error, including those not of type Error, is
+// considered to be of SeverityError
+func IsWarning(err error) bool {
+	var herr *Error
+	return errors.As(err, &herr) && herr.Severity == SeverityWarning
+}
+
+// newError returns Error of SeverityError with unknown offset, described
+// by format. Use %w to wrap sentinel errors such as ErrUnsafe
+func newError(field string, value string, rule string, rfc string, format string, a ...interface{}) *Error {
+	return &Error{
+		Field:  field,
+		Value:  value,
+		Offset: -1,
+		Rule:   rule,
+		RFC:    rfc,
+		Err:    fmt.Errorf(format, a...)}
+}
+
+// at sets offset of violation, where a negative offset is unknown
+func (e *Error) at(offset int) *Error {
+	if offset < 0 {
+		offset = -1
+	}
+	e.Offset = offset
+	return e
+}
+
+// warn sets severity to SeverityWarning
+func (e *Error) warn() *Error {
+	e.Severity = SeverityWarning
+	return e
+}
+
+// indexInvalid returns byte offset of the first rune in s
+// not satisfying valid, or -1 if all runes are valid.
+// valid is a character class check, eg syntax.IsVchar
+func indexInvalid(s string, valid func(s string) bool) int {
+	return strings.IndexFunc(s, func(r rune) bool { return !valid(string(r)) })
+}
+
+// syntaxOffset returns byte offset of a *syntax.SyntaxError, or -1
+func syntaxOffset(err error) int {
+	var se *syntax.SyntaxError
+	if errors.As(err, &se) {
+		return se.Pos
+	}
+	return -1
+}
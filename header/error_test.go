@@ -0,0 +1,73 @@
+package header_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	longLocal := "Bob <" + strings.Repeat("b", 65) + "@b.com>"
+	longID := "<" + strings.Repeat("a", 80) + "@b.com>"
+	for _, c := range []struct {
+		desc     string
+		h        header.Header
+		field    string
+		value    string
+		offset   int
+		rule     string
+		rfc      string
+		severity header.Severity
+	}{
+		{"address", header.Address{Field: header.AddressFrom, Value: "alice"},
+			"From", "alice", 5, "address-list", "RFC 5322 section 3.4", header.SeverityError},
+		{"address obsolete", header.Address{Field: header.AddressFrom, Value: "<@relay.com:john . public @ example . com>"},
+			"From", "<@relay.com:john . public @ example . com>", 1, "obs-addr-list", "RFC 5322 section 4.4", header.SeverityWarning},
+		{"local-part", header.Address{Field: header.AddressTo, Value: longLocal},
+			"To", longLocal, 5, "local-part", "RFC 5321 section 4.5.3.1.1", header.SeverityError},
+		{"unsafe", header.Subject("hi\r\nBcc: eve@a.com"),
+			"Subject", "hi\r\nBcc: eve@a.com", 2, "field-body", "RFC 5322 section 2.2", header.SeverityError},
+		{"unsafe field name", header.Field{FieldName: "X-Foo\r\nBcc", Value: "eve@a.com"},
+			"X-Foo Bcc", "X-Foo\r\nBcc", 5, "field-name", "RFC 5322 section 2.2", header.SeverityError},
+		{"unsafe custom value", header.CustomHeader{FieldName: "X-Foo", Value: "a\nb"},
+			"X-Foo", "a\nb", 1, "field-body", "RFC 5322 section 2.2", header.SeverityError},
+		{"field name", header.Field{FieldName: "X Foo", Value: "bar"},
+			"X Foo", "X Foo", 1, "field-name", "RFC 5322 section 2.2", header.SeverityError},
+		{"field body", header.Field{FieldName: "X-Foo", Value: "café"},
+			"X-Foo", "café", 3, "field-body", "RFC 5322 section 2.2", header.SeverityError},
+		{"custom colon", header.CustomHeader{FieldName: "X-Foo", Value: "a:b"},
+			"X-Foo", "a:b", 1, "unstructured", "RFC 5322 section 3.6.8", header.SeverityError},
+		{"msg-id syntax", header.InReplyTo{"<a@b.com>", "c@d.com"},
+			"In-Reply-To", "c@d.com", -1, "msg-id", "RFC 5322 section 3.6.4", header.SeverityError},
+		{"msg-id length", header.MessageID(longID),
+			"Message-ID", longID, 77, "msg-id", "RFC 5322 section 2.1.1", header.SeverityWarning},
+		{"list-id", header.ListID{ID: "list"},
+			"List-ID", "list", -1, "list-id", "RFC 2919 section 3", header.SeverityError},
+	} {
+		err := c.h.Validate()
+		var herr *header.Error
+		if !assert.True(t, errors.As(err, &herr), c.desc) {
+			continue
+		}
+		assert.Equal(t, c.field, herr.Field, c.desc)
+		assert.Equal(t, c.value, herr.Value, c.desc)
+		assert.Equal(t, c.offset, herr.Offset, c.desc)
+		assert.Equal(t, c.rule, herr.Rule, c.desc)
+		assert.Equal(t, c.rfc, herr.RFC, c.desc)
+		assert.Equal(t, c.severity, herr.Severity, c.desc)
+		assert.Equal(t, c.severity == header.SeverityWarning, header.IsWarning(err), c.desc)
+		assert.True(t, strings.HasPrefix(err.Error(), c.field+": "), c.desc)
+	}
+
+	// wrapped sentinel
+	err := header.CustomHeader{FieldName: "X-Foo", Value: "a\x00"}.Validate()
+	assert.ErrorIs(t, err, header.ErrUnsafe)
+	assert.EqualError(t, err, "X-Foo: must not contain CR, LF or NUL characters")
+
+	assert.False(t, header.IsWarning(errors.New("foo")))
+	assert.Equal(t, "warning", header.SeverityWarning.String())
+	assert.Equal(t, "error", header.SeverityError.String())
+}
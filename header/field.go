@@ -0,0 +1,69 @@
+package header
+
+import (
+	"strings"
+
+	"github.com/jimtsao/go-email/folder"
+)
+
+// Field represents a header field read from an existing message,
+// where the field body is kept as is other than being unfolded
+//
+// Usage:
+//
+//	f := Field{FieldName: "Received", Value: "from host.com by mx.example.com; ..."}
+//
+// Syntax:
+//
+//	field           =   field-name ":" field-body CRLF
+//	field-name      =   1*ftext
+//	field-body      =   *(*WSP VCHAR) *WSP
+type Field struct {
+	FieldName string
+	Value     string
+}
+
+// Name returns header name in canonical form
+func (f Field) Name() string {
+	return CanonicalHeaderKey(f.FieldName)
+}
+
+func (f Field) Validate() error {
+	if err := checkUnsafe(f.FieldName, "field-name", f.FieldName); err != nil {
+		return err
+	}
+	if err := checkUnsafe(f.FieldName, "field-body", f.Value); err != nil {
+		return err
+	}
+
+	return validateField(f.FieldName, f.Value)
+}
+
+// validateField checks field name and body contain only valid characters
+func validateField(name string, value string) error {
+	nameValid := IsValidHeaderName(name)
+	valValid := IsValidHeaderValue(value)
+	if !nameValid && !valValid {
+		return newError(name, name, "field-name", "RFC 5322 section 2.2",
+			"invalid characters in header name and body").at(indexInvalidName(name))
+	} else if !nameValid {
+		return newError(name, name, "field-name", "RFC 5322 section 2.2",
+			"invalid characters in header name").at(indexInvalidName(name))
+	} else if !valValid {
+		return newError(name, value, "field-body", "RFC 5322 section 2.2",
+			"invalid characters in header body").at(indexInvalidValue(value))
+	}
+
+	return nil
+}
+
+func (f Field) String() string {
+	// format: name: word[1][space]word[1][space]word...
+	sb := &strings.Builder{}
+	fw := folder.New(sb)
+	fw.Write(Sanitise(f.FieldName) + ":")
+	for _, word := range strings.Split(Sanitise(f.Value), " ") {
+		fw.Write(folder.FWS(1), word)
+	}
+	return closeFolder(fw, sb)
+}
@@ -0,0 +1,229 @@
+package header
+
+import (
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	"github.com/jimtsao/go-email/folder"
+	"github.com/jimtsao/go-email/syntax"
+)
+
+type ListField string
+
+const (
+	ListHelp        ListField = "List-Help"
+	ListUnsubscribe ListField = "List-Unsubscribe"
+	ListSubscribe   ListField = "List-Subscribe"
+	ListPost        ListField = "List-Post"
+	ListOwner       ListField = "List-Owner"
+	ListArchive     ListField = "List-Archive"
+)
+
+// List represents a mailing list command header field (RFC 2369), each
+// containing one or more URIs in order of preference
+//
+// usage:
+//
+//	l := List{Field: ListUnsubscribe, URIs: []string{"mailto:leave@list.com", "https://list.com/leave?id=1"}}
+//	l := List{Field: ListPost, URIs: []string{"NO"}}
+//
+// Syntax:
+//
+//	list-field      =   field-name ":" [CFWS] "<" URI ">" [CFWS]
+//	                    *("," [CFWS] "<" URI ">" [CFWS]) CRLF
+//	list-post       =   "List-Post:" [CFWS] ("NO" / "<" URI ">"
+//	                    *("," [CFWS] "<" URI ">" [CFWS])) CRLF
+//
+// Only mailto, http and https URIs are accepted
+type List struct {
+	Field ListField
+	URIs  []string
+}
+
+func (l List) Name() string {
+	return string(l.Field)
+}
+
+func (l List) Validate() error {
+	if len(l.URIs) == 0 {
+		return newError(l.Name(), "", "List-URI", "RFC 2369 section 2", "must contain at least 1 URI")
+	}
+	if err := checkUnsafe(l.Name(), "field-body", l.URIs...); err != nil {
+		return err
+	}
+
+	// posting to list not allowed
+	if l.Field == ListPost && len(l.URIs) == 1 && l.URIs[0] == "NO" {
+		return nil
+	}
+
+	for _, u := range l.URIs {
+		if err := validateListURI(u); err != nil {
+			return newError(l.Name(), u, "List-URI", "RFC 2369 section 2", "%w", err)
+		}
+	}
+
+	return nil
+}
+
+func (l List) String() string {
+	// format: name:[1][space]<uri>,[1][space]<uri>...
+	sb := &strings.Builder{}
+	f := folder.New(sb)
+	f.Write(l.Name() + ":")
+	for i, u := range l.URIs {
+		u = Sanitise(u)
+		if i > 0 {
+			f.Write(",")
+		}
+		if l.Field == ListPost && u == "NO" {
+			f.Write(folder.FWS(1), u)
+		} else {
+			f.Write(folder.FWS(1), "<"+u+">")
+		}
+	}
+	return closeFolder(f, sb)
+}
+
+// validateListURI checks u is an absolute mailto, http or https URI
+// that may be enclosed in angle brackets without further escaping
+func validateListURI(u string) error {
+	if u == "" {
+		return fmt.Errorf("empty URI")
+	}
+	if !syntax.IsVchar(u) || strings.ContainsAny(u, "<>") {
+		return fmt.Errorf("URI contains invalid characters (%q)", u)
+	}
+
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return fmt.Errorf("invalid URI (%q): %w", u, err)
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "mailto":
+		if _, err := mail.ParseAddressList(parsed.Opaque); err != nil {
+			return fmt.Errorf("invalid mailto URI (%q): %w", u, err)
+		}
+	case "http", "https":
+		if parsed.Host == "" {
+			return fmt.Errorf("URI missing host (%q)", u)
+		}
+	case "":
+		return fmt.Errorf("URI missing scheme (%q)", u)
+	default:
+		return fmt.Errorf("unsupported URI scheme %q (%q)", parsed.Scheme, u)
+	}
+
+	return nil
+}
+
+// ListUnsubscribePost represents the 'List-Unsubscribe-Post' header field
+// (RFC 8058), signalling that the https List-Unsubscribe URI supports one-click
+// unsubscription by POST request. It should be accompanied by a List-Unsubscribe
+// header containing a https URI, and the message signed with DKIM covering both
+//
+// default output is List-Unsubscribe-Post: List-Unsubscribe=One-Click
+type ListUnsubscribePost struct{}
+
+func (l ListUnsubscribePost) Name() string {
+	return "List-Unsubscribe-Post"
+}
+
+func (l ListUnsubscribePost) Validate() error {
+	return nil
+}
+
+func (l ListUnsubscribePost) String() string {
+	return "List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n"
+}
+
+// ListID represents the 'List-Id' header field (RFC 2919), identifying
+// a mailing list independently of the host currently serving it
+//
+// usage:
+//
+//	l := ListID{Description: "Secret Club", ID: "secret.club.com"}
+//
+// Syntax:
+//
+//	list-id-header  =   "List-ID:" [phrase] CFWS "<" list-id ">" CRLF
+//	list-id         =   list-label "." list-id-namespace
+//	list-label      =   dot-atom-text
+//	list-id-namespace = domain-name / unmanaged-list-id-namespace
+//	unmanaged-list-id-namespace = "localhost"
+//	domain-name     =   dot-atom-text
+type ListID struct {
+	Description string // optional, may contain non-ascii characters
+	ID          string
+}
+
+func (l ListID) Name() string {
+	return "List-ID"
+}
+
+func (l ListID) Validate() error {
+	if err := checkUnsafe(l.Name(), "field-body", l.Description, l.ID); err != nil {
+		return err
+	}
+	id := strings.TrimSuffix(strings.TrimPrefix(l.ID, "<"), ">")
+	if !strings.Contains(id, ".") || !syntax.IsDotAtomText(id) {
+		return newError(l.Name(), l.ID, "list-id", "RFC 2919 section 3",
+			"list-id must be of form label.namespace (%q)", l.ID)
+	}
+	if len(id) > 255 {
+		return newError(l.Name(), l.ID, "list-id", "RFC 2919 section 3",
+			"list-id exceeds max length 255 bytes (%q)", id)
+	}
+	if !syntax.IsWordEncodable(l.Description) {
+		return newError(l.Name(), l.Description, "phrase", "RFC 2919 section 3",
+			"description must contain only printable or white space characters").at(indexInvalid(l.Description, syntax.IsWordEncodable))
+	}
+	return nil
+}
+
+func (l ListID) String() string {
+	id := "<" + strings.TrimSuffix(strings.TrimPrefix(Sanitise(l.ID), "<"), ">") + ">"
+	sb := &strings.Builder{}
+	f := folder.New(sb)
+	f.Write(l.Name() + ":")
+
+	// format: name:[1][space]phrase[2][space]<list-id>
+	desc := strings.TrimSpace(Sanitise(l.Description))
+	switch {
+	case desc == "":
+	case !syntax.IsASCII(desc):
+		f.Write(folder.FWS(1), folder.WordEncodable{
+			Decoded:      desc,
+			Enc:          mime.QEncoding,
+			MustEncode:   true,
+			FoldPriority: 3})
+	case isAtomPhrase(desc):
+		for _, w := range strings.Fields(desc) {
+			f.Write(folder.FWS(1), w)
+		}
+	default:
+		r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+		f.Write(folder.FWS(1), `"`+r.Replace(desc)+`"`)
+	}
+
+	if desc == "" {
+		f.Write(folder.FWS(1), id)
+	} else {
+		f.Write(folder.FWS(2), id)
+	}
+	return closeFolder(f, sb)
+}
+
+// isAtomPhrase reports whether s consists of atext words only
+func isAtomPhrase(s string) bool {
+	for _, w := range strings.Fields(s) {
+		if !syntax.IsAtext(w) {
+			return false
+		}
+	}
+	return true
+}
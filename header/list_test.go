@@ -0,0 +1,73 @@
+package header_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList(t *testing.T) {
+	h := header.List{Field: header.ListUnsubscribe, URIs: []string{
+		"mailto:leave@list.com?subject=unsubscribe",
+		"https://list.com/leave?id=1",
+	}}
+	assert.NoError(t, h.Validate())
+	want := "List-Unsubscribe: <mailto:leave@list.com?subject=unsubscribe>,\r\n" +
+		" <https://list.com/leave?id=1>\r\n"
+	assert.Equal(t, want, h.String())
+
+	h = header.List{Field: header.ListPost, URIs: []string{"NO"}}
+	assert.NoError(t, h.Validate())
+	assert.Equal(t, "List-Post: NO\r\n", h.String())
+
+	// validation
+	for _, uris := range [][]string{
+		{},
+		{""},
+		{"NO"},
+		{"list.com/leave"},
+		{"ftp://list.com/leave"},
+		{"https:///leave"},
+		{"https://list.com/leave me"},
+		{"https://list.com/<leave>"},
+		{"mailto:not an address"},
+	} {
+		h := header.List{Field: header.ListUnsubscribe, URIs: uris}
+		assert.Error(t, h.Validate(), uris)
+	}
+}
+
+func TestListUnsubscribePost(t *testing.T) {
+	h := header.ListUnsubscribePost{}
+	assert.NoError(t, h.Validate())
+	assert.Equal(t, "List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n", h.String())
+}
+
+func TestListID(t *testing.T) {
+	tcs := []struct {
+		h    header.ListID
+		want string
+	}{
+		{header.ListID{ID: "news.list.com"}, "List-ID: <news.list.com>\r\n"},
+		{header.ListID{Description: "Secret Club", ID: "<news.list.com>"}, "List-ID: Secret Club <news.list.com>\r\n"},
+		{header.ListID{Description: "Secret Club, Inc.", ID: "news.list.com"}, "List-ID: \"Secret Club, Inc.\" <news.list.com>\r\n"},
+		{header.ListID{Description: "Café", ID: "news.list.com"}, "List-ID: =?utf-8?q?Caf=C3=A9?= <news.list.com>\r\n"},
+	}
+	for _, tc := range tcs {
+		assert.NoError(t, tc.h.Validate(), tc.want)
+		assert.Equal(t, tc.want, tc.h.String())
+	}
+
+	// validation
+	for _, h := range []header.ListID{
+		{},
+		{ID: "localhost"},
+		{ID: "news list.com"},
+		{ID: strings.Repeat("a", 250) + ".list.com"},
+		{Description: "bad\x00", ID: "news.list.com"},
+	} {
+		assert.Error(t, h.Validate(), h.ID)
+	}
+}
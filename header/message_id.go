@@ -1,7 +1,7 @@
 package header
 
 import (
-	"fmt"
+	"encoding/base32"
 	"strings"
 
 	"github.com/jimtsao/go-email/folder"
@@ -36,22 +36,12 @@ func (m MessageID) Name() string {
 
 func (m MessageID) Validate() error {
 	id := msgid(m)
-	if err := id.validate(); err != nil {
-		return fmt.Errorf("%s: %w", m.Name(), err)
+	if err := id.validate(m.Name()); err != nil {
+		return err
 	}
 
 	// chars
-	nameValid := IsValidHeaderName(m.Name())
-	valValid := IsValidHeaderValue(id.string())
-	if !nameValid && !valValid {
-		return fmt.Errorf("%s: invalid characters in header name and body", m.Name())
-	} else if !nameValid {
-		return fmt.Errorf("%s: invalid characters in header name", m.Name())
-	} else if !valValid {
-		return fmt.Errorf("%s: invalid characters in header body", m.Name())
-	}
-
-	return nil
+	return validateField(m.Name(), id.string())
 }
 
 func (m MessageID) String() string {
@@ -59,6 +49,18 @@ func (m MessageID) String() string {
 	sb := &strings.Builder{}
 	f := folder.New(sb)
 	f.Write(m.Name()+":", folder.FWS(1), id)
-	f.Close()
-	return sb.String()
+	return closeFolder(f, sb)
+}
+
+var idEncoding = base32.NewEncoding("0123456789abcdefghijklmnopqrstuv").WithPadding(base32.NoPadding)
+
+// NewMessageID generates a globally unique Message-ID of the form:
+//
+//	<[unix time in base 36].[80 random bits in base 32]@domain>
+//
+// domain should be a fully qualified domain name the generating host
+// is responsible for, internationalised domains are converted to their
+// A-label form. If empty or invalid, "localhost" is used instead
+func NewMessageID(domain string) MessageID {
+	return (&Source{}).MessageID(domain)
 }
@@ -18,3 +18,20 @@ func TestMessageID(t *testing.T) {
 	want = "Message-ID:\r\n <i@iiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiii>\r\n"
 	assert.Equal(t, want, m.String(), "folding")
 }
+
+func TestNewMessageID(t *testing.T) {
+	m := header.NewMessageID("example.com")
+	assert.NoError(t, m.Validate())
+	assert.Regexp(t, `^<[0-9a-z]+\.[0-9a-v]{16}@example\.com>$`, string(m))
+	assert.NotEqual(t, m, header.NewMessageID("example.com"), "unique")
+
+	// internationalised domain
+	m = header.NewMessageID("bücher.example")
+	assert.NoError(t, m.Validate())
+	assert.Regexp(t, `@xn--bcher-kva\.example>$`, string(m))
+
+	// default domain
+	m = header.NewMessageID("")
+	assert.NoError(t, m.Validate())
+	assert.Regexp(t, `@localhost>$`, string(m))
+}
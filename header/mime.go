@@ -69,6 +69,17 @@ func (m MIMEHeader) Name() string {
 }
 
 func (m MIMEHeader) Validate() error {
+	if err := checkUnsafe(m.Name(), "field-name", m.name); err != nil {
+		return err
+	}
+	values := []string{m.val}
+	for _, p := range m.params {
+		values = append(values, p.Attribute, p.Value)
+	}
+	if err := checkUnsafe(m.Name(), "field-body", values...); err != nil {
+		return err
+	}
+
 	if m.validate == nil {
 		return nil
 	}
@@ -79,19 +90,18 @@ func (m MIMEHeader) String() string {
 	// format: Content-name:[2][space]val;[1][space][3:param]
 	sb := &strings.Builder{}
 	f := folder.New(sb)
-	f.Write(m.Name()+":", folder.FWS(2), m.val)
+	f.Write(Sanitise(m.Name())+":", folder.FWS(2), Sanitise(m.val))
 
 	// params
 	for _, p := range m.params {
 		mp := folder.MIMEParam{
-			Attribute:    p.Attribute,
+			Attribute:    Sanitise(p.Attribute),
 			Val:          p.Value,
 			FoldPriority: 3}
 		f.Write(";", folder.FWS(1), mp)
 	}
 
-	f.Close()
-	return sb.String()
+	return closeFolder(f, sb)
 }
 
 // NewContentType returns Content-Type header:
@@ -122,10 +132,7 @@ func NewContentTransferEncoding(val string) MIMEHeader {
 //	msg-id     = [CFWS] "<" id-left "@" id-right ">" [CFWS]
 func NewContentID(val string) MIMEHeader {
 	validate := func() error {
-		if err := msgid(val).validate(); err != nil {
-			return fmt.Errorf("%s: %w", "Content-ID", err)
-		}
-		return nil
+		return msgid(val).validate("Content-ID")
 	}
 	return MIMEHeader{name: "ID", val: val, validate: validate}
 }
@@ -0,0 +1,190 @@
+package header
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrMalformed is wrapped by defects where a line of the header block
+// could not be read as part of a field, and so was discarded
+var ErrMalformed = errors.New("malformed header line")
+
+// RawField is a header field as read by Reader
+type RawField struct {
+	Name  string // field name, excluding any white space before the colon
+	Value string // unfolded field body, excluding leading white space and line ending
+	Raw   []byte // field exactly as read, including folding and line endings
+}
+
+// Field returns f as a Field, for use as a Header
+func (f RawField) Field() Field {
+	return Field{FieldName: f.Name, Value: f.Value}
+}
+
+// Reader splits a header block into fields, unfolding each as per
+// RFC 5322 section 2.2.3. The exact bytes of each field are retained,
+// as required to verify signatures such as DKIM (RFC 6376).
+//
+// Reading is lenient: bare LF line endings, lines exceeding 998 octets,
+// white space before the colon and invalid field names are accepted,
+// lines without a colon are discarded. Each is recorded in Defects
+//
+// usage:
+//
+//	r := header.NewReader(br)
+//	for {
+//		f, err := r.ReadField()
+//		if err == io.EOF {
+//			break // br is positioned at start of body
+//		} else if err != nil {
+//			return err
+//		}
+//		fields = append(fields, f)
+//	}
+//
+// Syntax:
+//
+//	fields          =   *field
+//	field           =   field-name ":" unstructured CRLF
+//	field-name      =   1*ftext
+//	ftext           =   %d33-57 / %d59-126
+//	obs-optional    =   field-name *WSP ":" unstructured CRLF
+type Reader struct {
+	Defects []*Error // violations encountered, in order read
+
+	r      *bufio.Reader
+	done   bool
+	bareLF bool // bare LF defect recorded
+}
+
+// NewReader returns Reader of header block r. If r is a *bufio.Reader
+// it is used directly, and is positioned at the start of the body once
+// ReadField returns io.EOF
+func NewReader(r io.Reader) *Reader {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &Reader{r: br}
+}
+
+// ReadField returns the next field, or io.EOF once the blank line
+// ending the header block, or end of input, is reached
+func (r *Reader) ReadField() (RawField, error) {
+	for !r.done {
+		line, err := r.readLine()
+		if err != nil {
+			return RawField{}, err
+		}
+
+		// blank line separates header from body
+		if len(trimEOL(line)) == 0 {
+			r.done = true
+			break
+		}
+
+		// continuation line without preceding field
+		if line[0] == ' ' || line[0] == '\t' {
+			r.defect(newError("", string(trimEOL(line)), "field", "RFC 5322 section 2.2.3",
+				"%w: continuation line without field", ErrMalformed))
+			continue
+		}
+
+		colon := bytes.IndexByte(line, ':')
+		if colon == -1 {
+			r.defect(newError("", string(trimEOL(line)), "field", "RFC 5322 section 2.2",
+				"%w: missing colon", ErrMalformed))
+			continue
+		}
+		name := bytes.TrimRight(line[:colon], " \t")
+		if len(name) == 0 {
+			r.defect(newError("", string(trimEOL(line)), "field-name", "RFC 5322 section 2.2",
+				"%w: missing field name", ErrMalformed))
+			continue
+		}
+
+		// unfold continuation lines
+		raw := line
+		value := append([]byte{}, trimEOL(line[colon+1:])...)
+		for r.continues() {
+			if line, err = r.readLine(); err != nil {
+				return RawField{}, err
+			}
+			raw = append(raw, line...)
+			value = append(value, trimEOL(line)...)
+		}
+
+		f := RawField{
+			Name:  string(name),
+			Value: string(bytes.TrimLeft(value, " \t")),
+			Raw:   raw,
+		}
+		r.checkName(f.Name, len(name) < colon)
+		return f, nil
+	}
+
+	return RawField{}, io.EOF
+}
+
+// readLine returns next line including line ending, recording defects
+// of line ending and length. io.EOF is returned only if no bytes remain
+func (r *Reader) readLine() ([]byte, error) {
+	line, err := r.r.ReadBytes('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	} else if err == io.EOF {
+		r.done = true
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content := trimEOL(line)
+	if !r.bareLF && bytes.HasSuffix(line, []byte("\n")) && !bytes.HasSuffix(line, []byte("\r\n")) {
+		r.bareLF = true
+		r.defect(newError("", string(content), "CRLF", "RFC 5322 section 2.1",
+			"bare LF line ending"))
+	}
+	if len(content) > 998 {
+		r.defect(newError("", string(content[:78])+"...", "text", "RFC 5322 section 2.1.1",
+			"line exceeds 998 octets (%d)", len(content)))
+	}
+	return line, nil
+}
+
+// continues reports whether next line is a continuation of the current field
+func (r *Reader) continues() bool {
+	b, err := r.r.Peek(1)
+	return err == nil && (b[0] == ' ' || b[0] == '\t')
+}
+
+// checkName records defects of field name, wsp is white space before colon
+func (r *Reader) checkName(name string, wsp bool) {
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c > 126 {
+			r.defect(newError(name, name, "ftext", "RFC 5322 section 2.2",
+				"8-bit byte in field name").at(i))
+			break
+		} else if c < 33 {
+			r.defect(newError(name, name, "ftext", "RFC 5322 section 2.2",
+				"invalid character %q in field name", c).at(i))
+			break
+		}
+	}
+	if wsp {
+		r.defect(newError(name, name, "obs-optional", "RFC 5322 section 4.5",
+			"white space before colon").warn())
+	}
+}
+
+func (r *Reader) defect(e *Error) {
+	r.Defects = append(r.Defects, e)
+}
+
+// trimEOL removes trailing CRLF or bare LF
+func trimEOL(b []byte) []byte {
+	b = bytes.TrimSuffix(b, []byte("\n"))
+	return bytes.TrimSuffix(b, []byte("\r"))
+}
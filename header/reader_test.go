@@ -0,0 +1,84 @@
+package header_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/stretchr/testify/assert"
+)
+
+func readFields(t *testing.T, r *header.Reader) []header.RawField {
+	var fields []header.RawField
+	for {
+		f, err := r.ReadField()
+		if err == io.EOF {
+			return fields
+		}
+		assert.NoError(t, err)
+		fields = append(fields, f)
+	}
+}
+
+func TestReader(t *testing.T) {
+	raw := "Subject: This\r\n is a test\r\n" +
+		"DKIM-Signature: v=1;\r\n\tb=abc\r\n" +
+		"To: a@b.com\r\n" +
+		"\r\n" +
+		"body\r\n"
+	br := bufio.NewReader(strings.NewReader(raw))
+	r := header.NewReader(br)
+	fields := readFields(t, r)
+	assert.Empty(t, r.Defects)
+	if assert.Len(t, fields, 3) {
+		assert.Equal(t, "Subject", fields[0].Name)
+		assert.Equal(t, "This is a test", fields[0].Value)
+		assert.Equal(t, "Subject: This\r\n is a test\r\n", string(fields[0].Raw))
+		assert.Equal(t, "v=1;\tb=abc", fields[1].Value)
+		assert.Equal(t, "DKIM-Signature: v=1;\r\n\tb=abc\r\n", string(fields[1].Raw))
+		assert.Equal(t, header.Field{FieldName: "To", Value: "a@b.com"}, fields[2].Field())
+	}
+
+	// positioned at body
+	body, _ := io.ReadAll(br)
+	assert.Equal(t, "body\r\n", string(body))
+	_, err := r.ReadField()
+	assert.Equal(t, io.EOF, err)
+
+	// no body
+	fields = readFields(t, header.NewReader(strings.NewReader("To: a@b.com")))
+	assert.Equal(t, []header.RawField{{Name: "To", Value: "a@b.com", Raw: []byte("To: a@b.com")}}, fields)
+}
+
+func TestReaderDefects(t *testing.T) {
+	raw := " orphan\n" +
+		"Subject : obsolete\n" +
+		"no colon\n" +
+		"X-Caf\xc3\xa9: 8bit\r\n" +
+		"X-Long: " + strings.Repeat("a", 1000) + "\r\n" +
+		": no name\r\n" +
+		"From: a@b.com\n" +
+		"\n"
+	r := header.NewReader(strings.NewReader(raw))
+	fields := readFields(t, r)
+
+	var names []string
+	for _, f := range fields {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"Subject", "X-Café", "X-Long", "From"}, names)
+	assert.Equal(t, "Subject : obsolete\n", string(fields[0].Raw))
+
+	var got []string
+	for _, d := range r.Defects {
+		got = append(got, d.Rule)
+	}
+	assert.Equal(t, []string{"CRLF", "field", "obs-optional", "field", "ftext", "text", "field-name"}, got)
+	assert.True(t, errors.Is(r.Defects[1], header.ErrMalformed))
+	assert.True(t, header.IsWarning(r.Defects[2]))
+	assert.Equal(t, 5, r.Defects[4].Offset)
+	assert.Equal(t, "X-Café: 8-bit byte in field name", r.Defects[4].Error())
+}
@@ -0,0 +1,101 @@
+package header
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnsafe is returned by Validate when a header field name or body
+// contains CR, LF or NUL. Such characters could end the field early,
+// injecting additional header fields or body content into a message
+//
+// Each header type also neutralises these characters when output,
+// see Sanitise, so that invalid input cannot alter message structure
+var ErrUnsafe = errors.New("must not contain CR, LF or NUL characters")
+
+// ContainsUnsafe reports whether s contains CR, LF or NUL
+func ContainsUnsafe(s string) bool {
+	return strings.ContainsAny(s, "\r\n\x00")
+}
+
+// Sanitise replaces each run of CR, LF and NUL characters
+// in s with a single space
+//
+// eg, "foo\r\nBcc: eve@secret.com" returns "foo Bcc: eve@secret.com"
+func Sanitise(s string) string {
+	if !ContainsUnsafe(s) {
+		return s
+	}
+
+	sb := strings.Builder{}
+	unsafe := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\r', '\n', 0:
+			if !unsafe {
+				sb.WriteByte(' ')
+			}
+			unsafe = true
+		default:
+			sb.WriteByte(s[i])
+			unsafe = false
+		}
+	}
+	return sb.String()
+}
+
+// SanitiseField ensures s, the output of Header.String, is a single
+// header field terminated by CRLF, or empty if s is empty. Line breaks other than folding
+// (CRLF followed by white space) are replaced as per Sanitise
+//
+// It guards against Header implementations which do not
+// neutralise unsafe characters themselves
+func SanitiseField(s string) string {
+	body := strings.TrimRight(s, "\r\n")
+	if body == "" {
+		return ""
+	} else if !ContainsUnsafe(body) {
+		return body + "\r\n"
+	}
+
+	sb := strings.Builder{}
+	for len(body) > 0 {
+		i := strings.IndexAny(body, "\r\n\x00")
+		if i == -1 {
+			sb.WriteString(body)
+			break
+		}
+		sb.WriteString(body[:i])
+		body = body[i:]
+
+		// folding white space
+		if strings.HasPrefix(body, "\r\n ") || strings.HasPrefix(body, "\r\n\t") {
+			sb.WriteString("\r\n")
+			body = body[2:]
+			continue
+		}
+
+		// unsafe run
+		j := 0
+		for j < len(body) && (body[j] == '\r' || body[j] == '\n' || body[j] == 0) {
+			j++
+		}
+		sb.WriteByte(' ')
+		body = body[j:]
+	}
+
+	sb.WriteString("\r\n")
+	return sb.String()
+}
+
+// checkUnsafe returns Error wrapping ErrUnsafe if any of the
+// values contain unsafe characters, rule names the part of the
+// field they belong to, eg "field-name" or "field-body"
+func checkUnsafe(name string, rule string, values ...string) error {
+	for _, v := range values {
+		if i := strings.IndexAny(v, "\r\n\x00"); i != -1 {
+			return newError(Sanitise(name), v, rule, "RFC 5322 section 2.2", "%w", ErrUnsafe).at(i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package header_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitise(t *testing.T) {
+	assert.True(t, header.ContainsUnsafe("a\rb"))
+	assert.True(t, header.ContainsUnsafe("a\x00b"))
+	assert.False(t, header.ContainsUnsafe("a\tb"))
+
+	assert.Equal(t, "hello world", header.Sanitise("hello world"))
+	assert.Equal(t, "foo Bcc: eve@secret.com", header.Sanitise("foo\r\nBcc: eve@secret.com"))
+	assert.Equal(t, "a b c d", header.Sanitise("a\nb\rc\x00\x00d"))
+
+	// folding retained, injected fields are not
+	assert.Equal(t, "", header.SanitiseField(""))
+	assert.Equal(t, "X: a\r\n", header.SanitiseField("X: a"))
+	assert.Equal(t, "X: a\r\n b\r\n", header.SanitiseField("X: a\r\n b\r\n"))
+	assert.Equal(t, "X: a Bcc: eve@secret.com\r\n", header.SanitiseField("X: a\r\nBcc: eve@secret.com\r\n"))
+	assert.Equal(t, "X: a  body\r\n", header.SanitiseField("X: a\r\n\r\n body\r\n"))
+}
+
+func TestHeaderInjection(t *testing.T) {
+	inject := "a\r\nBcc: eve@secret.com"
+	for _, c := range []struct {
+		desc string
+		h    header.Header
+	}{
+		{"custom value", header.CustomHeader{FieldName: "X-Custom", Value: inject}},
+		{"custom name", header.CustomHeader{FieldName: "X-Custom: a\r\nBcc", Value: "eve@secret.com"}},
+		{"field", header.Field{FieldName: "X-Field", Value: inject}},
+		{"subject", header.Subject(inject)},
+		{"address", header.Address{Field: header.AddressTo, Value: "bob@a.com\r\nBcc: eve@secret.com"}},
+		{"sender", header.Address{Field: header.AddressSender, Value: "bob@a.com\nBcc: eve@secret.com"}},
+		{"message-id", header.MessageID("<a@b.com>\r\nBcc: eve@secret.com")},
+		{"in-reply-to", header.InReplyTo{"<a@b.com>\r\nBcc: eve@secret.com"}},
+		{"references", header.References{"<a@b.com>", "<c@d.com>\nBcc: eve@secret.com"}},
+		{"content-type", header.NewContentType("text/plain\r\nBcc: eve@secret.com", nil)},
+		{"content-type param", header.NewContentType("text/plain", header.NewMIMEParams("charset", inject))},
+		{"content-id", header.NewContentID("<a@b.com>\r\nBcc: eve@secret.com")},
+		{"content-disposition", header.NewContentDisposition(false, "a.txt\r\nBcc: eve@secret.com", nil)},
+		{"list", header.List{Field: header.ListUnsubscribe, URIs: []string{"mailto:a@b.com>\r\nBcc: <eve@secret.com"}}},
+		{"list-id", header.ListID{Description: inject, ID: "list.b.com"}},
+		{"nul", header.CustomHeader{FieldName: "X-Custom", Value: "a\x00b"}},
+	} {
+		err := c.h.Validate()
+		assert.ErrorIs(t, err, header.ErrUnsafe, c.desc)
+
+		s := c.h.String()
+		body := strings.TrimSuffix(s, "\r\n")
+		assert.NotContains(t, s, "\r\nBcc", c.desc)
+		assert.NotContains(t, s, "\x00", c.desc)
+		assert.NotContains(t, strings.ReplaceAll(body, "\r\n ", ""), "\r", c.desc)
+		assert.NotContains(t, strings.ReplaceAll(body, "\r\n ", ""), "\n", c.desc)
+	}
+}
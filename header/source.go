@@ -0,0 +1,69 @@
+package header
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	mrand "math/rand"
+	"strconv"
+	"time"
+)
+
+// Source supplies the randomness and current time used to generate
+// values such as Message-IDs, dates and multipart boundaries. The zero
+// value, and a nil *Source, use crypto/rand and time.Now
+//
+// usage, reproducible output for golden file tests:
+//
+//	src := header.NewSeededSource(1, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//	m.Source = src
+type Source struct {
+	Rand  io.Reader        // defaults to crypto/rand.Reader
+	Clock func() time.Time // defaults to time.Now
+}
+
+// NewSeededSource returns a deterministic Source, with pseudo random
+// bytes generated from seed and a clock fixed at t. Output depends on
+// the order values are generated in. It is not safe for concurrent use
+func NewSeededSource(seed int64, t time.Time) *Source {
+	return &Source{
+		Rand:  mrand.New(mrand.NewSource(seed)),
+		Clock: func() time.Time { return t },
+	}
+}
+
+// Read fills b with random bytes, implementing io.Reader.
+// It panics if the underlying random source fails
+func (s *Source) Read(b []byte) (int, error) {
+	r := rand.Reader
+	if s != nil && s.Rand != nil {
+		r = s.Rand
+	}
+	if _, err := io.ReadFull(r, b); err != nil {
+		panic(fmt.Sprintf("source: random read failed: %v", err))
+	}
+	return len(b), nil
+}
+
+// Now returns the current time according to Clock
+func (s *Source) Now() time.Time {
+	if s != nil && s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+// MessageID generates a Message-ID as per NewMessageID,
+// using the time and randomness of s
+func (s *Source) MessageID(domain string) MessageID {
+	right, err := DomainToASCII(domain)
+	if err != nil || right == "" {
+		right = "localhost"
+	}
+
+	b := make([]byte, 10)
+	s.Read(b)
+	left := strconv.FormatInt(s.Now().UnixNano(), 36) + "." + idEncoding.EncodeToString(b)
+
+	return MessageID(fmt.Sprintf("<%s@%s>", left, right))
+}
@@ -0,0 +1,33 @@
+package header_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSource(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// seeded sources generate identical sequences
+	a := header.NewSeededSource(1, now)
+	b := header.NewSeededSource(1, now)
+	assert.Equal(t, now, a.Now())
+	for i := 0; i < 3; i++ {
+		id := a.MessageID("a.com")
+		assert.Equal(t, id, b.MessageID("a.com"))
+		assert.NoError(t, id.Validate())
+	}
+	assert.NotEqual(t, header.NewSeededSource(2, now).MessageID("a.com"), header.NewSeededSource(1, now).MessageID("a.com"))
+
+	// zero and nil sources use crypto/rand and time.Now
+	var nilSource *header.Source
+	assert.NotEqual(t, nilSource.MessageID("a.com"), (&header.Source{}).MessageID("a.com"))
+	assert.WithinDuration(t, time.Now(), nilSource.Now(), time.Minute)
+	buf := make([]byte, 8)
+	n, err := nilSource.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, n)
+}
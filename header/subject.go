@@ -1,5 +1,7 @@
 package header
 
+import "github.com/jimtsao/go-email/syntax"
+
 // Subject represents the 'Subject' header field
 //
 // Syntax:
@@ -18,17 +20,49 @@ func (s Subject) Name() string {
 // and satisfy 'unstructured' definition, we check that
 // it can be word encoded instead
 func (s Subject) Validate() error {
+	if err := checkUnsafe(s.Name(), "field-body", string(s)); err != nil {
+		return err
+	}
+	if !syntax.IsWordEncodable(string(s)) {
+		return newError(s.Name(), string(s), "encoded-word", "RFC 2047 section 5",
+			"must contain only printable or white space characters").at(indexInvalid(string(s), syntax.IsWordEncodable))
+	}
+	return nil
+}
+
+func (s Subject) String() string {
 	return CustomHeader{
 		FieldName:     s.Name(),
 		Value:         string(s),
 		WordEncodable: true,
-	}.Validate()
+	}.String()
 }
 
-func (s Subject) String() string {
+// CharsetSubject represents the 'Subject' header field, where any
+// encoded-words use Charset rather than utf-8 if it can represent
+// Subject, eg for recipients expecting a legacy character set
+//
+// Usage:
+//
+//	h := CharsetSubject{Subject: "Café", Charset: "iso-8859-1"}
+type CharsetSubject struct {
+	Subject Subject
+	Charset string
+}
+
+func (s CharsetSubject) Name() string {
+	return s.Subject.Name()
+}
+
+func (s CharsetSubject) Validate() error {
+	return s.Subject.Validate()
+}
+
+func (s CharsetSubject) String() string {
 	return CustomHeader{
 		FieldName:     s.Name(),
-		Value:         string(s),
+		Value:         string(s.Subject),
 		WordEncodable: true,
+		Charset:       s.Charset,
 	}.String()
 }
@@ -14,6 +14,7 @@ func TestSubject(t *testing.T) {
 		valid bool
 	}{
 		{"secret message", "Subject: secret message\r\n", true},
+		{"Re: secret message", "Subject: Re: secret message\r\n", true},
 		{"éve is\tlistening", "Subject: =?utf-8?q?=C3=A9ve_is=09listening?=\r\n", true},
 		{"\v\f", "Subject: =?utf-8?q?=0B=0C?=\r\n", false},
 	} {
@@ -28,3 +29,20 @@ func TestSubject(t *testing.T) {
 		assert.Equal(t, c.want, got, c.input)
 	}
 }
+
+func TestCharsetSubject(t *testing.T) {
+	for _, c := range []struct {
+		input   string
+		charset string
+		want    string
+	}{
+		{"secret message", "iso-8859-1", "Subject: secret message\r\n"},
+		{"éve is listening", "ISO-8859-1", "Subject: =?iso-8859-1?q?=E9ve_is_listening?=\r\n"},
+		{"Привет", "koi8-r", "Subject: =?koi8-r?q?=F0=D2=C9=D7=C5=D4?=\r\n"},
+		{"Привет", "iso-8859-1", "Subject: =?utf-8?q?=D0=9F=D1=80=D0=B8=D0=B2=D0=B5=D1=82?=\r\n"},
+	} {
+		h := header.CharsetSubject{Subject: header.Subject(c.input), Charset: c.charset}
+		assert.NoError(t, h.Validate(), c.input)
+		assert.Equal(t, c.want, h.String(), c.input)
+	}
+}
@@ -0,0 +1,133 @@
+package header
+
+import (
+	"strings"
+
+	"github.com/jimtsao/go-email/folder"
+	"github.com/jimtsao/go-email/syntax"
+)
+
+// MaxReferences is the number of msg-id retained in the References
+// header field before older ids, other than the first, are discarded
+const MaxReferences = 20
+
+// InReplyTo represents the 'In-Reply-To' header field, containing
+// the Message-ID of each message being replied to
+//
+// Usage:
+//
+//	h := InReplyTo{"<parent@host.com>"}
+//
+// Syntax:
+//
+//	in-reply-to     =   "In-Reply-To:" 1*msg-id CRLF
+type InReplyTo []string
+
+func (r InReplyTo) Name() string {
+	return "In-Reply-To"
+}
+
+func (r InReplyTo) Validate() error {
+	return msgidList(r).validate(r.Name())
+}
+
+func (r InReplyTo) String() string {
+	return msgidList(r).string(r.Name())
+}
+
+// References represents the 'References' header field, containing
+// the Message-ID of each message in the thread, oldest first
+//
+// Usage:
+//
+//	h := References{"<root@host.com>", "<parent@host.com>"}
+//
+// Syntax:
+//
+//	references      =   "References:" 1*msg-id CRLF
+//
+// RFC 5322 section 3.6.4 describes the contents as the parent's References
+// followed by the parent's Message-ID. To prevent threads from growing the
+// field without bound, output retains the first msg-id (thread root) and
+// the most recent ones up to MaxReferences, as RFC 5537 section 3.4.4
+// recommends for the equivalent Netnews header.
+type References []string
+
+func (r References) Name() string {
+	return "References"
+}
+
+func (r References) Validate() error {
+	return msgidList(r).validate(r.Name())
+}
+
+func (r References) String() string {
+	return msgidList(r.Truncate(MaxReferences)).string(r.Name())
+}
+
+// Truncate returns at most n msg-id, consisting of the first
+// and the n-1 most recent, discarding those in between
+func (r References) Truncate(n int) References {
+	if n <= 0 || len(r) <= n {
+		return r
+	}
+	if n == 1 {
+		return r[:1]
+	}
+	t := References{r[0]}
+	return append(t, r[len(r)-n+1:]...)
+}
+
+// msgidList is a list of msg-id, where folding may occur before each id
+type msgidList []string
+
+func (l msgidList) validate(name string) error {
+	if len(l) == 0 {
+		return newError(name, "", "msg-id", "RFC 5322 section 3.6.4", "must contain at least 1 msg-id")
+	}
+
+	for _, id := range l {
+		if err := msgid(id).validate(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l msgidList) string(name string) string {
+	// format: name:[1][space]id[1][space]id...
+	sb := &strings.Builder{}
+	f := folder.New(sb)
+	f.Write(name + ":")
+	for _, id := range l {
+		f.Write(folder.FWS(1), msgid(id).string())
+	}
+	return closeFolder(f, sb)
+}
+
+// ParseMsgIDs returns each msg-id contained in the field body of
+// a Message-ID, In-Reply-To or References header. Obsolete syntax
+// (RFC 5322 section 4.5.4) is normalised, eg "<a . b @ c.com>" returns
+// "<a.b@c.com>". Otherwise comments, white space and any text outside
+// of angle brackets are ignored
+func ParseMsgIDs(s string) []string {
+	if ids, err := (syntax.Parser{Lenient: true}).MsgIDList(s); err == nil {
+		return ids
+	}
+
+	var ids []string
+	for {
+		start := strings.IndexByte(s, '<')
+		if start == -1 {
+			break
+		}
+		end := strings.IndexByte(s[start:], '>')
+		if end == -1 {
+			break
+		}
+		ids = append(ids, s[start:start+end+1])
+		s = s[start+end+1:]
+	}
+	return ids
+}
@@ -0,0 +1,50 @@
+package header_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInReplyTo(t *testing.T) {
+	h := header.InReplyTo{"<parent@host.com>"}
+	assert.NoError(t, h.Validate())
+	assert.Equal(t, "In-Reply-To: <parent@host.com>\r\n", h.String())
+
+	// validation
+	assert.Error(t, header.InReplyTo{}.Validate(), "empty")
+	assert.Error(t, header.InReplyTo{"<parent@host.com>", "parent@host.com"}.Validate(), "invalid id")
+}
+
+func TestReferences(t *testing.T) {
+	// folding
+	id := "<" + strings.Repeat("i", 30) + "@host>"
+	h := header.References{id, id, id}
+	assert.NoError(t, h.Validate())
+	want := fmt.Sprintf("References: %[1]s\r\n %[1]s %[1]s\r\n", id)
+	assert.Equal(t, want, h.String(), "folding")
+
+	// truncation
+	var ids header.References
+	for i := 0; i < header.MaxReferences+5; i++ {
+		ids = append(ids, fmt.Sprintf("<%d@host>", i))
+	}
+	got := ids.Truncate(header.MaxReferences)
+	assert.Len(t, got, header.MaxReferences)
+	assert.Equal(t, "<0@host>", got[0], "thread root retained")
+	assert.Equal(t, "<6@host>", got[1], "older ids discarded")
+	assert.Equal(t, ids[len(ids)-1], got[len(got)-1], "most recent retained")
+	assert.NotContains(t, ids.String(), "<5@host>")
+	assert.Equal(t, header.References{"<0@host>"}, ids.Truncate(1))
+}
+
+func TestParseMsgIDs(t *testing.T) {
+	got := header.ParseMsgIDs("<root@host.com>\r\n <parent@host.com> (comment) <child@host.com>")
+	assert.Equal(t, []string{"<root@host.com>", "<parent@host.com>", "<child@host.com>"}, got)
+	assert.Nil(t, header.ParseMsgIDs(""))
+	assert.Nil(t, header.ParseMsgIDs("<unterminated@host.com"))
+	assert.Equal(t, []string{"<a.b@c.com>"}, header.ParseMsgIDs("<a . b @ c.com>"))
+}
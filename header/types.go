@@ -3,8 +3,6 @@
 package header
 
 import (
-	"errors"
-	"fmt"
 	"strings"
 
 	"github.com/jimtsao/go-email/syntax"
@@ -12,25 +10,30 @@ import (
 
 type msgid string
 
-func (m msgid) validate() error {
-	// folding not permitted within actual content of msg-id
-	// smtp allows only 78 octets excluding crlf
-	// folding allowed before actual message id, so max content length is 78 - folding white space
-	maxOctetLen := 78 - 1
-	id := string(m)
-	id = strings.TrimSpace(id)
-	if len(id) > maxOctetLen {
-		return fmt.Errorf("id must not exceed %d octets, has %d octets", maxOctetLen, len(id))
+// validate checks msg-id of header field name
+func (m msgid) validate(name string) error {
+	if err := checkUnsafe(name, "field-body", string(m)); err != nil {
+		return err
 	}
 
 	// msg-id syntax
+	id := strings.TrimSpace(string(m))
 	if !syntax.IsMsgID(id) {
-		return errors.New("id invalid syntax")
+		return newError(name, id, "msg-id", "RFC 5322 section 3.6.4", "id invalid syntax (%q)", id)
+	}
+
+	// folding not permitted within actual content of msg-id
+	// lines should not exceed 78 octets excluding crlf
+	// folding allowed before actual message id, so max content length is 78 - folding white space
+	maxOctetLen := 78 - 1
+	if len(id) > maxOctetLen {
+		return newError(name, id, "msg-id", "RFC 5322 section 2.1.1",
+			"id should not exceed %d octets, has %d octets", maxOctetLen, len(id)).at(maxOctetLen).warn()
 	}
 
 	return nil
 }
 
 func (m msgid) string() string {
-	return strings.TrimSpace(string(m))
+	return strings.TrimSpace(Sanitise(string(m)))
 }
@@ -1,5 +1,11 @@
 package header
 
+import (
+	"strings"
+
+	"github.com/jimtsao/go-email/folder"
+)
+
 // CanonicalHeaderKey returns a canonical form of the key
 // whereby the first letter of each word is capitalised
 //
@@ -75,6 +81,31 @@ func IsValidHeaderValue(s string) bool {
 	return true
 }
 
+// indexInvalidName returns byte offset of the first invalid character in
+// header name s, or where s exceeds max length, or -1 if s is valid
+func indexInvalidName(s string) int {
+	for i, c := range []byte(s) {
+		if !isValidHeaderNameByte(c) {
+			return i
+		}
+	}
+	if len(s) > 77 {
+		return 77
+	}
+	return -1
+}
+
+// indexInvalidValue returns byte offset of the first invalid
+// character in header body s, or -1 if s is valid
+func indexInvalidValue(s string) int {
+	for i, c := range []byte(s) {
+		if !isValidHeaderValueByte(c) {
+			return i
+		}
+	}
+	return -1
+}
+
 // valid range from !(33) to ~(126) except :(58)
 func isValidHeaderNameByte(c byte) bool {
 	return '!' <= c && c <= '~' && c != ':'
@@ -84,3 +115,23 @@ func isValidHeaderNameByte(c byte) bool {
 func isValidHeaderValueByte(c byte) bool {
 	return c == 9 || (' ' <= c && c <= '~')
 }
+
+// Value returns the unfolded field body of a header,
+// with leading and trailing white space removed
+//
+// eg, "Subject: foo\r\n bar\r\n" returns "foo bar"
+func Value(h Header) string {
+	_, body, _ := strings.Cut(h.String(), ":")
+	body = strings.ReplaceAll(body, "\r\n", "")
+	return strings.Trim(body, " \t")
+}
+
+// closeFolder closes f and returns the field written to sb. If the
+// field could not be folded it is returned unfolded, so that String
+// always produces output, see mime.Entity.Encode to detect this
+func closeFolder(f *folder.Folder, sb *strings.Builder) string {
+	if f.Close(); f.Err != nil {
+		return f.Unfolded() + "\r\n"
+	}
+	return sb.String()
+}
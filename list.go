@@ -0,0 +1,78 @@
+package goemail
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jimtsao/go-email/header"
+)
+
+// MailingList sets the List-* header fields of bulk or list mail
+//
+// usage:
+//
+//	e.List = &MailingList{
+//		ID:          "news.secret.com",
+//		Unsubscribe: []string{"mailto:leave@secret.com", "https://secret.com/leave?id=1"},
+//		OneClick:    true,
+//	}
+type MailingList struct {
+	ID          string // List-Id, eg news.secret.com
+	Description string // optional List-Id description
+	Unsubscribe []string
+	// OneClick adds List-Unsubscribe-Post (RFC 8058), indicating
+	// the https Unsubscribe URI accepts a one-click POST request
+	OneClick  bool
+	Subscribe []string
+	Help      []string
+	Post      []string // use "NO" if posting is not allowed
+	Owner     []string
+	Archive   []string
+}
+
+// Headers returns List-* header fields for each non empty field
+func (l *MailingList) Headers() []header.Header {
+	var hh []header.Header
+	if l.ID != "" {
+		hh = append(hh, header.ListID{Description: l.Description, ID: l.ID})
+	}
+	for _, v := range []struct {
+		field header.ListField
+		uris  []string
+	}{
+		{header.ListUnsubscribe, l.Unsubscribe},
+		{header.ListSubscribe, l.Subscribe},
+		{header.ListHelp, l.Help},
+		{header.ListPost, l.Post},
+		{header.ListOwner, l.Owner},
+		{header.ListArchive, l.Archive},
+	} {
+		if len(v.uris) > 0 {
+			hh = append(hh, header.List{Field: v.field, URIs: v.uris})
+		}
+	}
+	if l.OneClick {
+		hh = append(hh, header.ListUnsubscribePost{})
+	}
+	return hh
+}
+
+// Validate checks requirements spanning multiple fields,
+// syntax of each header is checked by Email.Validate
+func (l *MailingList) Validate() error {
+	if !l.OneClick {
+		return nil
+	}
+	for _, u := range l.Unsubscribe {
+		if strings.HasPrefix(strings.ToLower(u), "https://") {
+			return nil
+		}
+	}
+	return &header.Error{
+		Field:  "List-Unsubscribe-Post",
+		Value:  strings.Join(l.Unsubscribe, ", "),
+		Offset: -1,
+		Rule:   "List-Unsubscribe",
+		RFC:    "RFC 8058 section 3.1",
+		Err:    errors.New("one-click requires a https List-Unsubscribe URI")}
+}
@@ -0,0 +1,186 @@
+// package merge generates personalised messages in bulk, rendering
+// a template once for each recipient record (mail merge)
+package merge
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+
+	goemail "github.com/jimtsao/go-email"
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/template"
+)
+
+// errEmptyAddress is returned for records without a recipient address
+var errEmptyAddress = errors.New("missing recipient address")
+
+// Message is a generated message and the record it was generated from
+type Message struct {
+	Index     int // zero based position of record
+	Record    Record
+	Email     *goemail.Email
+	MessageID string
+}
+
+// Sink receives each valid generated message, such as a transport
+// sending the message or a directory the message is saved to
+type Sink interface {
+	Write(m *Message) error
+}
+
+// SinkFunc adapts a function to a Sink, eg to send via a transport:
+//
+//	merge.SinkFunc(func(m *merge.Message) error {
+//		return smtp.SendMail(addr, auth, from, []string{m.Record.Get("email")}, []byte(m.Email.Raw()))
+//	})
+type SinkFunc func(m *Message) error
+
+func (f SinkFunc) Write(m *Message) error {
+	return f(m)
+}
+
+// Dir is a Sink saving each message as a .eml file in the directory,
+// named by record index. Messages failing Email.Bytes are not saved
+type Dir string
+
+func (d Dir) Write(m *Message) error {
+	b, err := m.Email.Bytes()
+	if err != nil {
+		return err
+	}
+	name := filepath.Join(string(d), fmt.Sprintf("%06d.eml", m.Index))
+	return os.WriteFile(name, b, 0644)
+}
+
+// RecordError contains the errors preventing a message being
+// generated for a record
+type RecordError struct {
+	Index  int
+	Record Record
+	Errs   []error
+}
+
+func (e *RecordError) Error() string {
+	s := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		s[i] = err.Error()
+	}
+	return fmt.Sprintf("record %d: %s", e.Index, strings.Join(s, "; "))
+}
+
+// Result summarises a batch run
+type Result struct {
+	Written int            // messages written to sink
+	Errors  []*RecordError // records skipped
+}
+
+// Batch renders Template for each record. Records failing to render
+// or Email.Validate, other than by warnings, are skipped and reported
+// in Result, allowing the rest of the batch to proceed
+type Batch struct {
+	Template *template.Template
+	From     string
+	// ToField is the record field containing the recipient
+	// address, defaults to "email"
+	ToField string
+	// NameField is the optional record field containing
+	// the recipient display name
+	NameField string
+	// Domain of generated Message-IDs, defaults to From domain
+	Domain string
+	// Source of generated Message-IDs, dates and boundaries,
+	// eg header.NewSeededSource for reproducible output
+	Source *header.Source
+	// Prepare is called with each rendered message before
+	// validation, eg to add attachments or headers. Optional
+	Prepare func(e *goemail.Email, r Record) error
+}
+
+// Run generates a message for each record read from r, writing those
+// that are valid to sink. Run stops and returns an error if reading
+// records or writing to sink fails
+func (b *Batch) Run(r Reader, sink Sink) (*Result, error) {
+	res := &Result{}
+	for i := 0; ; i++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return res, nil
+		} else if err != nil {
+			return res, err
+		}
+
+		m, errs := b.message(i, rec)
+		if len(errs) > 0 {
+			res.Errors = append(res.Errors, &RecordError{Index: i, Record: rec, Errs: errs})
+			continue
+		}
+		if err := sink.Write(m); err != nil {
+			return res, fmt.Errorf("merge: record %d: %w", i, err)
+		}
+		res.Written++
+	}
+}
+
+// message renders and validates message for record
+func (b *Batch) message(i int, rec Record) (*Message, []error) {
+	e, err := b.Template.Render(rec)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	// recipient
+	field := b.ToField
+	if field == "" {
+		field = "email"
+	}
+	to := strings.TrimSpace(rec.Get(field))
+	if to == "" {
+		return nil, []error{fmt.Errorf("%s: %w", field, errEmptyAddress)}
+	}
+	if b.NameField != "" {
+		if name := strings.TrimSpace(rec.Get(b.NameField)); name != "" {
+			to = (&mail.Address{Name: name, Address: to}).String()
+		}
+	}
+
+	e.From = b.From
+	e.To = to
+	e.AutoDate = true
+	e.Source = b.Source
+	id := b.Source.MessageID(b.domain())
+	e.AddHeader(id)
+
+	if b.Prepare != nil {
+		if err := b.Prepare(e, rec); err != nil {
+			return nil, []error{err}
+		}
+	}
+	// warnings do not prevent sending, as with Email.Bytes
+	var errs []error
+	for _, err := range e.Validate() {
+		if !header.IsWarning(err) {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return &Message{Index: i, Record: rec, Email: e, MessageID: string(id)}, nil
+}
+
+func (b *Batch) domain() string {
+	if b.Domain != "" {
+		return b.Domain
+	}
+	if addr, err := mail.ParseAddress(b.From); err == nil {
+		_, domain, _ := strings.Cut(addr.Address, "@")
+		return domain
+	}
+	return ""
+}
@@ -0,0 +1,198 @@
+package merge_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	goemail "github.com/jimtsao/go-email"
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/merge"
+	"github.com/jimtsao/go-email/mime"
+	"github.com/jimtsao/go-email/template"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVReader(t *testing.T) {
+	r := merge.NewCSVReader(strings.NewReader("email, name\nbob@b.com,Bob\n\"carol@c.com\",\"Carol, C\"\n"))
+	rec, err := r.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, merge.Record{"email": "bob@b.com", "name": "Bob"}, rec)
+	rec, err = r.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, "Carol, C", rec.Get("name"))
+	_, err = r.Read()
+	assert.Equal(t, io.EOF, err)
+
+	// empty and malformed
+	_, err = merge.NewCSVReader(strings.NewReader("")).Read()
+	assert.Equal(t, io.EOF, err)
+	_, err = merge.NewCSVReader(strings.NewReader("email,name\nbob@b.com\n")).Read()
+	assert.Error(t, err)
+}
+
+func TestJSONReader(t *testing.T) {
+	r := merge.NewJSONReader(strings.NewReader("{\"email\":\"bob@b.com\",\"items\":[1,2]}\n\n{\"email\":\"carol@c.com\"}\n[1]\n"))
+	rec, err := r.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, "bob@b.com", rec.Get("email"))
+	assert.Equal(t, []interface{}{1.0, 2.0}, rec["items"])
+	assert.Equal(t, "", rec.Get("items"), "not a string")
+	rec, err = r.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, "carol@c.com", rec.Get("email"))
+	_, err = r.Read()
+	assert.Error(t, err, "not an object")
+	assert.Contains(t, err.Error(), "line 4")
+}
+
+func TestBatch(t *testing.T) {
+	b := &merge.Batch{
+		Template:  template.Must(template.New("Hi {{.name}}", "Hello {{.name}}", "")),
+		From:      "news@a.com",
+		NameField: "name",
+	}
+	records := "email,name\n" +
+		"bob@b.com,Bob\n" +
+		"not an address,Eve\n" +
+		",Nobody\n" +
+		"carol@c.com,Carol\n"
+
+	var msgs []*merge.Message
+	res, err := b.Run(merge.NewCSVReader(strings.NewReader(records)), merge.SinkFunc(func(m *merge.Message) error {
+		msgs = append(msgs, m)
+		return nil
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, res.Written)
+	assert.Len(t, msgs, 2)
+
+	// per record errors
+	assert.Len(t, res.Errors, 2)
+	assert.Equal(t, 1, res.Errors[0].Index)
+	assert.Equal(t, "Eve", res.Errors[0].Record.Get("name"))
+	assert.Contains(t, res.Errors[0].Error(), "record 1: To:")
+	var herr *header.Error
+	if assert.ErrorAs(t, res.Errors[0].Errs[0], &herr) {
+		assert.Equal(t, `"Eve" <"not an address"@>`, herr.Value)
+		assert.Equal(t, strings.Index(herr.Value, "@>")+1, herr.Offset, "missing domain")
+	}
+	assert.Equal(t, 2, res.Errors[1].Index)
+	assert.Contains(t, res.Errors[1].Error(), "missing recipient address")
+
+	// personalised with unique message id
+	assert.Equal(t, 0, msgs[0].Index)
+	assert.Equal(t, 3, msgs[1].Index)
+	assert.Equal(t, "Hi Bob", msgs[0].Email.Subject)
+	assert.Equal(t, `"Bob" <bob@b.com>`, msgs[0].Email.To)
+	assert.NotEqual(t, msgs[0].MessageID, msgs[1].MessageID)
+	assert.True(t, strings.HasSuffix(msgs[0].MessageID, "@a.com>"))
+	raw := msgs[0].Email.Raw()
+	assert.Contains(t, raw, "\r\nMessage-ID: "+msgs[0].MessageID+"\r\n")
+	assert.True(t, strings.HasPrefix(raw, "MIME-Version: 1.0\r\nDate: "))
+
+	// render errors are per record
+	b.Template = template.Must(template.New("Hi {{.name}}", "Hello {{.name}}", ""))
+	res, err = b.Run(merge.NewJSONReader(strings.NewReader(`{"email":"bob@b.com"}`)), merge.SinkFunc(func(m *merge.Message) error {
+		return nil
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, res.Written)
+	assert.Len(t, res.Errors, 1)
+
+	// warnings do not skip record, eg Message-ID longer than recommended
+	b.Domain = "mail." + strings.Repeat("a", 63) + ".com"
+	res, err = b.Run(merge.NewJSONReader(strings.NewReader(`{"email":"dave@d.com","name":"Dave"}`)), merge.SinkFunc(func(m *merge.Message) error {
+		errs := m.Email.Validate()
+		if assert.Len(t, errs, 1) {
+			assert.True(t, header.IsWarning(errs[0]))
+		}
+		return nil
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, res.Written)
+	assert.Empty(t, res.Errors)
+	b.Domain = ""
+
+	// prepare hook
+	b.Prepare = func(e *goemail.Email, r merge.Record) error {
+		e.Cc = r.Get("cc")
+		return nil
+	}
+	res, err = b.Run(merge.NewJSONReader(strings.NewReader(`{"email":"bob@b.com","name":"Bob","cc":"<bad"}`)), merge.SinkFunc(func(m *merge.Message) error {
+		return nil
+	}))
+	assert.NoError(t, err)
+	assert.Len(t, res.Errors, 1)
+	assert.Contains(t, res.Errors[0].Error(), "Cc:")
+
+	// sink failure stops batch
+	b.Prepare = nil
+	sinkErr := errors.New("connection refused")
+	res, err = b.Run(merge.NewCSVReader(strings.NewReader(records)), merge.SinkFunc(func(m *merge.Message) error {
+		return sinkErr
+	}))
+	assert.ErrorIs(t, err, sinkErr)
+	assert.Equal(t, 0, res.Written)
+}
+
+func TestDir(t *testing.T) {
+	dir := t.TempDir()
+	b := &merge.Batch{
+		Template: template.Must(template.New("Hi", "Hello {{.name}}", "")),
+		From:     "news@a.com",
+		Domain:   "mail.a.com",
+	}
+	res, err := b.Run(merge.NewCSVReader(strings.NewReader("email,name\nbob@b.com,Bob\n")), merge.Dir(dir))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, res.Written)
+
+	f, err := os.Open(filepath.Join(dir, "000000.eml"))
+	assert.NoError(t, err)
+	defer f.Close()
+	e, err := mime.ReadEntity(f)
+	assert.NoError(t, err)
+	assert.Equal(t, "<bob@b.com>", e.Get("To"))
+	assert.True(t, strings.HasSuffix(e.Get("Message-ID"), "@mail.a.com>"))
+	content, err := e.Content()
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello Bob", string(content))
+
+	// invalid message is not written
+	m := &merge.Message{Index: 1, Email: goemail.New()}
+	m.Email.From = "news@a.com"
+	m.Email.To = "not an address"
+	m.Email.Subject = "Hi"
+	m.Email.Body = "Hello Bob"
+	assert.Error(t, merge.Dir(dir).Write(m))
+	_, err = os.Stat(filepath.Join(dir, "000001.eml"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestBatchSource(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	run := func() []string {
+		b := &merge.Batch{
+			Template: template.Must(template.New("Hi {{.name}}", "Hello {{.name}}", "<p>Hello {{.name}}</p>")),
+			From:     "news@a.com",
+			Source:   header.NewSeededSource(1, now),
+		}
+		var raws []string
+		_, err := b.Run(merge.NewCSVReader(strings.NewReader("email,name\nbob@b.com,Bob\ncarol@c.com,Carol\n")),
+			merge.SinkFunc(func(m *merge.Message) error {
+				raws = append(raws, m.Email.Raw())
+				return nil
+			}))
+		assert.NoError(t, err)
+		return raws
+	}
+
+	raws := run()
+	assert.Len(t, raws, 2)
+	assert.Equal(t, raws, run())
+	assert.Contains(t, raws[0], "Date: Mon, 1 Jan 2024 00:00:00 +0000\r\n")
+}
@@ -0,0 +1,100 @@
+package merge
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Record is the data of a single recipient, keyed by field name
+type Record map[string]interface{}
+
+// Get returns value of field, or an empty string if
+// it is missing or not a string
+func (r Record) Get(field string) string {
+	s, _ := r[field].(string)
+	return s
+}
+
+// Reader iterates over recipient records, returning io.EOF
+// once there are no more records
+type Reader interface {
+	Read() (Record, error)
+}
+
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+// NewCSVReader returns a Reader of CSV data, where the first row
+// contains field names. Values are read as strings
+func NewCSVReader(r io.Reader) Reader {
+	return &csvReader{r: csv.NewReader(r)}
+}
+
+func (c *csvReader) Read() (Record, error) {
+	if c.header == nil {
+		header, err := c.r.Read()
+		if err == io.EOF {
+			return nil, io.EOF
+		} else if err != nil {
+			return nil, fmt.Errorf("merge: csv header: %w", err)
+		}
+		for i := range header {
+			header[i] = strings.TrimSpace(header[i])
+		}
+		c.header = header
+	}
+
+	row, err := c.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	} else if err != nil {
+		return nil, fmt.Errorf("merge: csv: %w", err)
+	}
+
+	rec := make(Record, len(row))
+	for i, v := range row {
+		rec[c.header[i]] = v
+	}
+	return rec, nil
+}
+
+type jsonReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// NewJSONReader returns a Reader of JSON lines data, where each
+// non blank line contains a JSON object
+func NewJSONReader(r io.Reader) Reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	return &jsonReader{s: s}
+}
+
+func (j *jsonReader) Read() (Record, error) {
+	for j.s.Scan() {
+		j.line++
+		line := strings.TrimSpace(j.s.Text())
+		if line == "" {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return nil, fmt.Errorf("merge: json line %d: %w", j.line, err)
+		}
+		if rec == nil {
+			return nil, fmt.Errorf("merge: json line %d: expected object", j.line)
+		}
+		return rec, nil
+	}
+	if err := j.s.Err(); err != nil {
+		return nil, fmt.Errorf("merge: json: %w", err)
+	}
+	return nil, io.EOF
+}
@@ -0,0 +1,148 @@
+package mime
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jimtsao/go-email/header"
+)
+
+// singleFields may occur at most once in a message,
+// as per the table in RFC 5322 section 3.6
+var singleFields = []string{
+	"Date", "From", "Sender", "Reply-To", "To", "Cc", "Bcc",
+	"Message-ID", "In-Reply-To", "References", "Subject",
+}
+
+// CheckMessage checks e as a whole message, reporting violations of rules
+// spanning multiple header fields or parts, which Header.Validate cannot
+// detect. Errors are of type *header.Error:
+//
+//   - exactly one Date and From field, where a missing Date is a
+//     warning as submission servers add it (RFC 6409 section 8.1)
+//   - Sender present if From contains more than one mailbox
+//   - at most one of each field limited to a single occurrence
+//   - MIME-Version present if Content-* fields are used
+//   - Content-ID unique across all parts
+//   - multipart boundary not occurring within any of its parts
+//
+// Messages embedded in message/rfc822 parts are not checked
+// as whole messages, though their parts are
+func CheckMessage(e *Entity) []error {
+	var errs []error
+	count := map[string]int{} // by lower case field name
+	usesMIME, hasVersion := false, false
+	for _, h := range e.Headers {
+		name := strings.ToLower(h.Name())
+		count[name]++
+		if strings.HasPrefix(name, "content-") {
+			usesMIME = true
+		} else if name == "mime-version" {
+			hasVersion = true
+		}
+	}
+
+	// required fields
+	for _, name := range []string{"Date", "From"} {
+		if count[strings.ToLower(name)] == 0 {
+			herr := &header.Error{
+				Field:  name,
+				Offset: -1,
+				Rule:   fieldRule(name),
+				RFC:    "RFC 5322 section 3.6",
+				Err:    errors.New("field is required")}
+			if name == "Date" {
+				herr.Severity = header.SeverityWarning
+			}
+			errs = append(errs, herr)
+		}
+	}
+
+	// single occurrence
+	for _, name := range singleFields {
+		if n := count[strings.ToLower(name)]; n > 1 {
+			errs = append(errs, &header.Error{
+				Field:  name,
+				Value:  e.Get(name),
+				Offset: -1,
+				Rule:   fieldRule(name),
+				RFC:    "RFC 5322 section 3.6",
+				Err:    fmt.Errorf("field must not occur more than once, occurs %d times", n)})
+		}
+	}
+
+	// sender required for multiple authors
+	if from, err := header.ParseAddressList(e.Get("From")); err == nil && len(from) > 1 && count["sender"] == 0 {
+		errs = append(errs, &header.Error{
+			Field:  "Sender",
+			Value:  e.Get("From"),
+			Offset: -1,
+			Rule:   "sender",
+			RFC:    "RFC 5322 section 3.6.2",
+			Err:    errors.New("field is required when From contains more than one mailbox")})
+	}
+
+	if usesMIME && !hasVersion {
+		errs = append(errs, &header.Error{
+			Field:  "MIME-Version",
+			Offset: -1,
+			Rule:   "version",
+			RFC:    "RFC 2045 section 4",
+			Err:    errors.New("field is required when Content-* fields are used")})
+	}
+
+	return append(errs, checkParts(e)...)
+}
+
+// fieldRule returns name of ABNF rule defining field
+func fieldRule(name string) string {
+	if name == "Date" {
+		return "orig-date"
+	}
+	return strings.ToLower(name)
+}
+
+// checkParts checks Content-ID uniqueness and boundary
+// delimiters of e and each of its descendants
+func checkParts(e *Entity) []error {
+	var errs []error
+	ids := map[string]bool{}
+	e.Walk(func(p *Entity) error {
+		for _, h := range p.Headers {
+			if !strings.EqualFold(h.Name(), "Content-ID") {
+				continue
+			}
+			id := header.Value(h)
+			if ids[id] {
+				errs = append(errs, &header.Error{
+					Field:  "Content-ID",
+					Value:  id,
+					Offset: -1,
+					Rule:   "id",
+					RFC:    "RFC 2045 section 7",
+					Err:    fmt.Errorf("must be unique, %s occurs more than once", id)})
+			}
+			ids[id] = true
+		}
+
+		mb, ok := p.Body.(*multipartBody)
+		if !ok {
+			return nil
+		}
+		delim := "--" + mb.boundary
+		for i, part := range mb.parts {
+			if s := part.String(); strings.HasPrefix(s, delim) || strings.Contains(s, "\n"+delim) {
+				errs = append(errs, &header.Error{
+					Field:  "Content-Type",
+					Value:  mb.boundary,
+					Offset: -1,
+					Rule:   "boundary",
+					RFC:    "RFC 2046 section 5.1.1",
+					Err:    fmt.Errorf("boundary must not occur within part %d", i+1)})
+			}
+		}
+		return nil
+	})
+	return errs
+}
@@ -0,0 +1,84 @@
+package mime_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+	"github.com/stretchr/testify/assert"
+)
+
+// fields returns the Field of each header.Error
+func fields(errs []error) []string {
+	var ff []string
+	for _, err := range errs {
+		var herr *header.Error
+		if errors.As(err, &herr) {
+			ff = append(ff, herr.Field)
+		}
+	}
+	return ff
+}
+
+func TestCheckMessage(t *testing.T) {
+	date := header.Date(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	from := header.Address{Field: header.AddressFrom, Value: "alice@a.com"}
+
+	// conformant
+	e := mime.NewEntity([]header.Header{date, from, header.Subject("hi")}, "hello")
+	assert.Empty(t, mime.CheckMessage(e))
+
+	// missing date and from
+	e = mime.NewEntity([]header.Header{header.Subject("hi")}, "hello")
+	errs := mime.CheckMessage(e)
+	assert.Equal(t, []string{"Date", "From"}, fields(errs))
+	var herr *header.Error
+	assert.ErrorAs(t, errs[0], &herr)
+	assert.Equal(t, "orig-date", herr.Rule)
+	assert.Equal(t, "RFC 5322 section 3.6", herr.RFC)
+	assert.True(t, header.IsWarning(errs[0]))
+	assert.False(t, header.IsWarning(errs[1]))
+
+	// duplicate single fields, matched case insensitively
+	e = mime.NewEntity([]header.Header{date, from, header.Subject("a"), header.Subject("b"),
+		header.MessageID("<1@a.com>"), header.Field{FieldName: "message-id", Value: "<2@a.com>"},
+		header.CustomHeader{FieldName: "Comments", Value: "a"}, header.CustomHeader{FieldName: "Comments", Value: "b"}}, "")
+	assert.Equal(t, []string{"Message-ID", "Subject"}, fields(mime.CheckMessage(e)))
+
+	// sender required for multiple authors
+	multi := header.Address{Field: header.AddressFrom, Value: "alice@a.com, bob@b.com"}
+	e = mime.NewEntity([]header.Header{date, multi}, "")
+	assert.Equal(t, []string{"Sender"}, fields(mime.CheckMessage(e)))
+	e.Headers = append(e.Headers, header.Address{Field: header.AddressSender, Value: "alice@a.com"})
+	assert.Empty(t, mime.CheckMessage(e))
+	obs := header.Field{FieldName: "From", Value: "alice@a.com,, bob . b @ b.com"}
+	e = mime.NewEntity([]header.Header{date, obs}, "")
+	assert.Equal(t, []string{"Sender"}, fields(mime.CheckMessage(e)), "obsolete syntax")
+
+	// mime version
+	e = mime.NewEntity([]header.Header{date, from, header.NewContentType("text/plain", nil)}, "")
+	assert.Equal(t, []string{"MIME-Version"}, fields(mime.CheckMessage(e)))
+	e.Headers = append(e.Headers, header.MIMEVersion{})
+	assert.Empty(t, mime.CheckMessage(e))
+
+	// content-id unique across parts
+	img := func() *mime.Entity {
+		return mime.NewEntity([]header.Header{header.NewContentType("image/png", nil),
+			header.NewContentID("<logo@a.com>")}, "")
+	}
+	e = mime.NewMultipartRelated([]header.Header{header.MIMEVersion{}, date, from}, []*mime.Entity{img(), img()})
+	assert.Equal(t, []string{"Content-ID"}, fields(mime.CheckMessage(e)))
+
+	// boundary within part
+	text := mime.NewEntity(nil, "hello")
+	e = mime.NewMultipartMixed([]header.Header{header.MIMEVersion{}, date, from}, []*mime.Entity{text})
+	assert.Empty(t, mime.CheckMessage(e))
+	_, params := e.ContentType()
+	text.Body = mime.String("hello\r\n--" + params["boundary"] + "--\r\n")
+	errs = mime.CheckMessage(e)
+	assert.Equal(t, []string{"Content-Type"}, fields(errs))
+	assert.ErrorAs(t, errs[0], &herr)
+	assert.Equal(t, "boundary", herr.Rule)
+}
@@ -0,0 +1,100 @@
+package mime
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jimtsao/go-email/folder"
+	"github.com/jimtsao/go-email/header"
+)
+
+// MaxLineOctets is the maximum length of a line in octets excluding CRLF,
+// as per RFC 5322 section 2.1.1
+const MaxLineOctets = 998
+
+// ErrLineTooLong is returned by Encode when a header field cannot be
+// folded, or a body line is not encoded, to within MaxLineOctets octets
+var ErrLineTooLong = folder.ErrLineTooLong
+
+// Encode validates and writes the entity to w. Unlike String, which
+// always produces output, Encode returns an error rather than writing
+// a malformed entity:
+//
+//   - a header field fails Validate, other than with a warning
+//   - a header field is not a single CRLF terminated field, eg a
+//     header.Header implementation that does not neutralise line breaks
+//   - a line exceeds MaxLineOctets octets
+//
+// Parts of multipart entities and messages embedded in message/rfc822
+// entities are encoded in the same manner. Nothing is written to w
+// unless the whole entity is encoded successfully
+func (e *Entity) Encode(w io.Writer) error {
+	sb := &strings.Builder{}
+	if err := e.encode(sb); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (e *Entity) encode(sb *strings.Builder) error {
+	// header fields
+	for _, h := range e.Headers {
+		if err := h.Validate(); err != nil && !header.IsWarning(err) {
+			return err
+		}
+		s := h.String()
+		if s != header.SanitiseField(s) {
+			return fmt.Errorf("%s: %w", header.Sanitise(h.Name()), header.ErrUnsafe)
+		}
+		if err := checkLineLen(s); err != nil {
+			return fmt.Errorf("%s: %w", h.Name(), err)
+		}
+		sb.WriteString(s)
+	}
+	sb.WriteString("\r\n")
+
+	// body
+	switch b := e.Body.(type) {
+	case *Entity:
+		return b.encode(sb)
+	case *multipartBody:
+		for idx, part := range b.parts {
+			if idx > 0 {
+				sb.WriteString("\r\n")
+			}
+			sb.WriteString("--" + b.boundary + "\r\n")
+			if err := part.encode(sb); err != nil {
+				return err
+			}
+		}
+		sb.WriteString("\r\n--" + b.boundary + "--")
+	default:
+		s := b.String()
+		if err := checkLineLen(s); err != nil {
+			return fmt.Errorf("body: %w", err)
+		}
+		sb.WriteString(s)
+	}
+
+	return nil
+}
+
+// checkLineLen returns ErrLineTooLong if any line of s
+// exceeds MaxLineOctets octets, excluding CRLF
+func checkLineLen(s string) error {
+	for n := 1; s != ""; n++ {
+		line := s
+		if i := strings.IndexByte(s, '\n'); i != -1 {
+			line, s = s[:i], s[i+1:]
+		} else {
+			s = ""
+		}
+		line = strings.TrimSuffix(line, "\r")
+		if len(line) > MaxLineOctets {
+			return fmt.Errorf("%w (line %d has %d octets)", ErrLineTooLong, n, len(line))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package mime_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+	"github.com/stretchr/testify/assert"
+)
+
+// rawHeader does not neutralise line breaks in its value
+type rawHeader string
+
+func (r rawHeader) Name() string    { return "X-Raw" }
+func (r rawHeader) Validate() error { return nil }
+func (r rawHeader) String() string  { return "X-Raw: " + string(r) + "\r\n" }
+
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestEntityEncode(t *testing.T) {
+	// output identical to String
+	text := mime.NewEntity([]header.Header{header.NewContentType("text/plain", nil)}, "hello")
+	inner := mime.NewEntity([]header.Header{header.Subject("inner")}, "world")
+	e := mime.NewMultipartMixed([]header.Header{header.MIMEVersion{}, header.Subject("hi")},
+		[]*mime.Entity{text, mime.NewMessageRFC822(inner)})
+	sb := &strings.Builder{}
+	assert.NoError(t, e.Encode(sb))
+	assert.Equal(t, e.String(), sb.String())
+
+	// invalid header
+	e = mime.NewEntity([]header.Header{header.Address{Field: header.AddressTo, Value: "bob"}}, "")
+	assert.Error(t, e.Encode(&strings.Builder{}))
+
+	// unfoldable header field
+	long := header.CustomHeader{FieldName: "X-Long", Value: strings.Repeat("a", 1000)}
+	assert.Equal(t, "X-Long: "+strings.Repeat("a", 1000)+"\r\n", long.String(), "unfolded")
+	e = mime.NewEntity([]header.Header{long}, "")
+	assert.ErrorIs(t, e.Encode(&strings.Builder{}), mime.ErrLineTooLong)
+
+	// unencoded body line
+	e = mime.NewEntity(nil, "ok\r\n"+strings.Repeat("a", 999)+"\r\n")
+	err := e.Encode(&strings.Builder{})
+	assert.ErrorIs(t, err, mime.ErrLineTooLong)
+	assert.Contains(t, err.Error(), "line 2")
+
+	// within a part, nothing written
+	part := mime.NewEntity(nil, strings.Repeat("a", 999))
+	e = mime.NewMultipartMixed(nil, []*mime.Entity{text, part})
+	sb = &strings.Builder{}
+	assert.ErrorIs(t, e.Encode(sb), mime.ErrLineTooLong)
+	assert.Empty(t, sb.String())
+
+	// header injection
+	e = mime.NewEntity([]header.Header{rawHeader("a\r\nBcc: eve@secret.com")}, "")
+	assert.ErrorIs(t, e.Encode(&strings.Builder{}), header.ErrUnsafe)
+
+	// writer error
+	e = mime.NewEntity(nil, "hello")
+	assert.EqualError(t, e.Encode(failWriter{}), "write failed")
+}
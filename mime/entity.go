@@ -50,7 +50,7 @@ func (e *Entity) String() string {
 	// header fields + blank line + body
 	sb := strings.Builder{}
 	for _, h := range e.Headers {
-		sb.WriteString(h.String())
+		sb.WriteString(header.SanitiseField(h.String()))
 	}
 	sb.WriteString("\r\n")
 	sb.WriteString(e.Body.String())
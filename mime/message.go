@@ -0,0 +1,39 @@
+package mime
+
+import (
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/syntax"
+)
+
+// NewMessageRFC822 returns a message/rfc822 entity encapsulating inner,
+// for forwarding a message as an attachment or returning it in a bounce
+//
+// RFC 2046 section 5.2.1 does not permit any encoding other than 7bit,
+// 8bit or binary for message/rfc822. Content-Transfer-Encoding is set to
+// 8bit if inner contains non us-ascii octets, otherwise 7bit. Any parts of
+// inner that require encoding should be encoded within inner itself
+func NewMessageRFC822(inner *Entity) *Entity {
+	return &Entity{
+		Headers: []header.Header{
+			header.NewContentType("message/rfc822", nil),
+			header.NewContentTransferEncoding(messageEncoding(inner)),
+		},
+		Body: inner,
+	}
+}
+
+// Embedded returns the message encapsulated by a message/rfc822
+// entity, see NewMessageRFC822, or nil otherwise
+func (e *Entity) Embedded() *Entity {
+	if inner, ok := e.Body.(*Entity); ok {
+		return inner
+	}
+	return nil
+}
+
+func messageEncoding(inner *Entity) string {
+	if syntax.IsASCII(inner.String()) {
+		return "7bit"
+	}
+	return "8bit"
+}
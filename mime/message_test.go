@@ -0,0 +1,87 @@
+package mime_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageRFC822(t *testing.T) {
+	inner := mime.NewEntity([]header.Header{
+		header.Address{Field: header.AddressFrom, Value: "a@a.com"},
+		header.Subject("foo"),
+	}, "hello world")
+	msg := mime.NewMessageRFC822(inner)
+	want := "Content-Type: message/rfc822\r\n" +
+		"Content-Transfer-Encoding: 7bit\r\n" +
+		"\r\n" +
+		"From: <a@a.com>\r\n" +
+		"Subject: foo\r\n" +
+		"\r\n" +
+		"hello world"
+	assert.Equal(t, want, msg.String())
+	assert.Equal(t, inner, msg.Embedded())
+
+	// 8bit
+	inner.Body = mime.String("héllo world")
+	msg = mime.NewMessageRFC822(inner)
+	assert.Equal(t, "8bit", msg.Get("Content-Transfer-Encoding"))
+
+	// nested within multipart
+	mixed := mime.NewMultipartMixed(nil, []*mime.Entity{
+		mime.NewEntity(nil, "see attached"),
+		msg,
+	})
+	parsed, err := mime.ReadEntity(strings.NewReader(mixed.String()))
+	assert.NoError(t, err)
+	parts := parsed.Parts()
+	assert.Len(t, parts, 2)
+	embedded := parts[1].Embedded()
+	assert.NotNil(t, embedded)
+	assert.Equal(t, "foo", embedded.Get("Subject"))
+	assert.Equal(t, "héllo world", embedded.Body.String())
+	assert.Nil(t, parts[0].Embedded())
+}
+
+func TestMessageRFC822Normalise(t *testing.T) {
+	// base64 not permitted, normalised on parse
+	raw := "Content-Type: message/rfc822\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		"U3ViamVjdDogZm9vDQoNCmhlbGxv"
+	msg, err := mime.ReadEntity(strings.NewReader(raw))
+	assert.NoError(t, err)
+	assert.Equal(t, "7bit", msg.Get("Content-Transfer-Encoding"))
+	assert.Equal(t, "foo", msg.Embedded().Get("Subject"))
+	assert.Equal(t, "hello", msg.Embedded().Body.String())
+}
+
+func TestMessageRFC822Truncated(t *testing.T) {
+	// original returned with a bounce, missing its close-delimiter
+	original := "Subject: foo\r\n" +
+		"Content-Type: multipart/mixed; boundary=zz\r\n" +
+		"\r\n" +
+		"--zz\r\n" +
+		"\r\n" +
+		"hello\r\n"
+	raw := "Content-Type: multipart/report; report-type=delivery-status; boundary=b\r\n" +
+		"\r\n" +
+		"--b\r\n" +
+		"\r\n" +
+		"delivery failed\r\n" +
+		"--b\r\n" +
+		"Content-Type: message/rfc822\r\n" +
+		"\r\n" +
+		original +
+		"--b--\r\n"
+	e, err := mime.ReadEntity(strings.NewReader(raw))
+	assert.NoError(t, err)
+	parts := e.Parts()
+	if assert.Len(t, parts, 2) {
+		assert.Nil(t, parts[1].Embedded())
+		assert.Equal(t, original, parts[1].Body.String()+"\r\n")
+	}
+}
@@ -16,11 +16,13 @@
 package mime
 
 import (
+	"crypto/rand"
 	"fmt"
-	"math/rand"
+	"io"
 	"net/http"
 	"strings"
 
+	"github.com/jimtsao/go-email/charset"
 	"github.com/jimtsao/go-email/header"
 )
 
@@ -29,10 +31,14 @@ const (
 	maxBoundaryLen = 70
 )
 
-// boundary charset
+// bcharsnospace, boundaries are generated without spaces
+// as some clients do not handle them correctly
 var bcharnospace = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789'()+_,-./:=?"
-var bchars = bcharnospace + " "
 
+// newBoundary returns a random boundary of n characters, that does
+// not occur as a delimiter within any of the parts, regenerating
+// it if necessary
+//
 // syntax:
 //
 //	boundary      := 0*69<bchars> bcharsnospace
@@ -40,7 +46,7 @@ var bchars = bcharnospace + " "
 //	bcharsnospace := DIGIT / ALPHA / "'" / "(" / ")" /
 //	                 "+" / "_" / "," / "-" / "." /
 //	                 "/" / ":" / "=" / "?"
-func randomBoundary(n int) string {
+func newBoundary(src io.Reader, n int, parts []*Entity) string {
 	// length checks
 	if n <= 0 {
 		return ""
@@ -48,21 +54,61 @@ func randomBoundary(n int) string {
 		n = maxBoundaryLen
 	}
 
-	// random generation
-	b := make([]byte, n-2)
-	for i := 0; i < len(b)-1; i++ {
-		b[i] = bchars[rand.Intn(len(bchars))]
+	if src == nil {
+		src = rand.Reader
+	}
+
+	// a deterministic source may repeat itself, give up eventually
+	// leaving the collision to be reported by CheckMessage
+	var boundary string
+	for i := 0; i < 10; i++ {
+		if boundary = randomBoundary(src, n); !containsDelimiter(parts, boundary) {
+			break
+		}
 	}
-	b[len(b)-1] = bcharnospace[rand.Intn(len(bcharnospace))]
+	return boundary
+}
 
+// randomBoundary returns n characters of bcharnospace read from src,
+// discarding bytes which would bias the distribution of characters
+func randomBoundary(src io.Reader, n int) string {
+	limit := 256 - 256%len(bcharnospace)
+	b := make([]byte, 0, n)
+	buf := make([]byte, n)
+	for len(b) < n {
+		if _, err := io.ReadFull(src, buf); err != nil {
+			panic(fmt.Sprintf("boundary: random source failed: %v", err))
+		}
+		for _, c := range buf {
+			if int(c) < limit && len(b) < n {
+				b = append(b, bcharnospace[int(c)%len(bcharnospace)])
+			}
+		}
+	}
 	return string(b)
 }
 
+// containsDelimiter reports whether boundary occurs at the
+// start of any line within the encoded parts
+func containsDelimiter(parts []*Entity, boundary string) bool {
+	delim := "--" + boundary
+	for _, p := range parts {
+		if s := p.String(); strings.HasPrefix(s, delim) || strings.Contains(s, "\n"+delim) {
+			return true
+		}
+	}
+	return false
+}
+
 // DetectContentType returns content type and charset if applicable
-// It splits the two unlike http.DetectContentType
-func DetectContentType(data []byte) (ctype string, charset string) {
+// It splits the two unlike http.DetectContentType. The charset of
+// text is detected by charset.Detect, rather than assumed to be utf-8
+func DetectContentType(data []byte) (ctype string, cs string) {
 	ct := http.DetectContentType(data)
-	ct, cs, _ := strings.Cut(ct, "; charset=")
+	ct, cs, _ = strings.Cut(ct, "; charset=")
+	if strings.HasPrefix(ct, "text/") {
+		cs = charset.Detect(data, ct)
+	}
 	return ct, cs
 }
 
@@ -123,13 +169,37 @@ func NewMultipartRelated(headers []header.Header, parts []*Entity) *Entity {
 	return NewMultipart("related", headers, parts)
 }
 
+// NewMultipartReport returns multipart/report entity (RFC 6522) for the
+// given report-type. Parts should consist of a human readable part, a
+// machine parsable report and optionally the original message or headers
+func NewMultipartReport(reportType string, headers []header.Header, parts []*Entity) *Entity {
+	return NewMultipartReportFrom(nil, reportType, headers, parts)
+}
+
+// NewMultipartReportFrom returns a multipart/report entity as per
+// NewMultipartReport, reading the random bytes of its boundary from src
+func NewMultipartReportFrom(src io.Reader, reportType string, headers []header.Header, parts []*Entity) *Entity {
+	return newMultipart(src, "report", header.NewMIMEParams("report-type", reportType), headers, parts)
+}
+
 // NewMultipart returns an entity with content-type set as multipart/subtype
 func NewMultipart(subtype string, headers []header.Header, parts []*Entity) *Entity {
+	return newMultipart(nil, subtype, nil, headers, parts)
+}
+
+// NewMultipartFrom returns a multipart/subtype entity as per NewMultipart,
+// reading the random bytes of its boundary from src, eg a *header.Source
+// or math/rand for reproducible output. crypto/rand is used if src is nil
+func NewMultipartFrom(src io.Reader, subtype string, headers []header.Header, parts []*Entity) *Entity {
+	return newMultipart(src, subtype, nil, headers, parts)
+}
+
+func newMultipart(src io.Reader, subtype string, params []header.MIMEParam, headers []header.Header, parts []*Entity) *Entity {
 	pre := fmt.Sprintf("Content-Type: multipart/%s; boundary=", subtype)
-	boundary := randomBoundary(maxLineLen - len(pre))
+	boundary := newBoundary(src, maxLineLen-len(pre)-2, parts)
 	return &Entity{
 		Headers: append(headers, header.NewContentType(
 			"multipart/"+subtype,
-			header.NewMIMEParams("boundary", boundary))),
+			append(header.NewMIMEParams("boundary", boundary), params...))),
 		Body: &multipartBody{boundary: boundary, parts: parts}}
 }
@@ -1,7 +1,10 @@
 package mime_test
 
 import (
+	"io"
+	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/jimtsao/go-email/header"
 	"github.com/jimtsao/go-email/mime"
@@ -45,3 +48,42 @@ func TestMultipartNested(t *testing.T) {
 		"\r\n--.*?--"
 	assert.Regexp(t, want, got)
 }
+
+func TestMultipartReport(t *testing.T) {
+	text := mime.NewEntity(nil, "foo")
+	report := mime.NewMultipartReport("delivery-status", nil, []*mime.Entity{text})
+	mediatype, params := report.ContentType()
+	assert.Equal(t, "multipart/report", mediatype)
+	assert.Equal(t, "delivery-status", params["report-type"])
+	assert.NotEmpty(t, params["boundary"])
+	assert.Regexp(t, `^Content-Type: multipart/report;\s+boundary=.*?;\s+report-type=delivery-status\r\n`, report.String())
+}
+
+func TestMultipartBoundary(t *testing.T) {
+	boundary := func(e *mime.Entity) string {
+		_, params := e.ContentType()
+		return params["boundary"]
+	}
+
+	// crypto/rand, no spaces
+	b := boundary(mime.NewMultipartMixed(nil, nil))
+	assert.Len(t, b, 36)
+	assert.NotContains(t, b, " ")
+	assert.NotEqual(t, b, boundary(mime.NewMultipartMixed(nil, nil)))
+
+	// deterministic source
+	src := func() io.Reader { return rand.New(rand.NewSource(1)) }
+	assert.Equal(t, boundary(mime.NewMultipartFrom(src(), "mixed", nil, nil)),
+		boundary(mime.NewMultipartFrom(src(), "mixed", nil, nil)))
+	assert.Equal(t, boundary(mime.NewMultipartReportFrom(src(), "delivery-status", nil, nil)),
+		boundary(mime.NewMultipartReportFrom(src(), "delivery-status", nil, nil)))
+
+	// regenerated on collision with part content
+	first := boundary(mime.NewMultipartFrom(src(), "mixed", nil, nil))
+	part := mime.NewEntity(nil, "foo\r\n--"+first+"\r\nbar")
+	mixed := mime.NewMultipartFrom(src(), "mixed", nil, []*mime.Entity{part})
+	assert.NotEqual(t, first, boundary(mixed))
+	assert.Empty(t, mime.CheckMessage(mime.NewMultipartMixed(
+		[]header.Header{header.MIMEVersion{}, header.Date(time.Now()), header.Address{Field: header.AddressFrom, Value: "a@a.com"}},
+		[]*mime.Entity{mixed})))
+}
@@ -0,0 +1,234 @@
+package mime
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	stdmime "mime"
+	"mime/quotedprintable"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jimtsao/go-email/charset"
+	"github.com/jimtsao/go-email/header"
+)
+
+// ReadEntity parses a message or body part. Header fields are read
+// as header.Field, multipart bodies are split into their parts and
+// message/rfc822 bodies are parsed as an embedded Entity, if valid. Other body
+// content is kept in its transfer encoded form, use Content to
+// retrieve the decoded form
+//
+// Both CRLF and bare LF line endings are accepted
+func ReadEntity(r io.Reader) (*Entity, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseEntity(b)
+}
+
+func parseEntity(b []byte) (*Entity, error) {
+	hh, body, err := parseHeader(b)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Entity{Headers: hh, Body: String(body)}
+	mediatype, params := e.ContentType()
+	if strings.HasPrefix(mediatype, "multipart/") {
+		boundary := params["boundary"]
+		if boundary == "" {
+			return nil, fmt.Errorf("mime: %s missing boundary", mediatype)
+		}
+		parts, err := parseMultipart(body, boundary)
+		if err != nil {
+			return nil, err
+		}
+		e.Body = &multipartBody{boundary: boundary, parts: parts}
+	}
+
+	// encapsulated message (message/global is the RFC 6532 equivalent),
+	// some clients incorrectly apply base64 or quoted-printable, in
+	// which case the encoding is normalised. Messages which cannot be
+	// parsed, such as the truncated original returned with a bounce,
+	// are kept as an opaque body rather than failing the outer entity
+	if mediatype == "message/rfc822" || mediatype == "message/global" {
+		content, err := e.Content()
+		if err != nil {
+			return e, nil
+		}
+		inner, err := parseEntity(content)
+		if err != nil {
+			return e, nil
+		}
+		e.Body = inner
+
+		cte := strings.ToLower(e.Get("Content-Transfer-Encoding"))
+		if cte == "base64" || cte == "quoted-printable" {
+			for i, h := range e.Headers {
+				if strings.EqualFold(h.Name(), "Content-Transfer-Encoding") {
+					e.Headers[i] = header.NewContentTransferEncoding(messageEncoding(inner))
+				}
+			}
+		}
+	}
+
+	return e, nil
+}
+
+// parseHeader splits header block from body and unfolds each field.
+// Lines which are not part of a field are an error
+func parseHeader(b []byte) ([]header.Header, []byte, error) {
+	br := bufio.NewReader(bytes.NewReader(b))
+	r := header.NewReader(br)
+	var hh []header.Header
+	for {
+		f, err := r.ReadField()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		hh = append(hh, f.Field())
+	}
+
+	for _, d := range r.Defects {
+		if errors.Is(d, header.ErrMalformed) {
+			return nil, nil, fmt.Errorf("mime: %w %q", d.Err, d.Value)
+		}
+	}
+
+	body, err := io.ReadAll(br)
+	return hh, body, err
+}
+
+// parseMultipart splits body at each dash-boundary line, discarding
+// preamble and epilogue. The line ending preceeding each boundary is
+// part of the delimiter and so not included in the body part
+func parseMultipart(body []byte, boundary string) ([]*Entity, error) {
+	dash := []byte("--" + boundary)
+	var parts []*Entity
+	var start = -1
+	closed := false
+
+	for pos := 0; pos < len(body) && !closed; {
+		// next line
+		end := len(body)
+		if i := bytes.IndexByte(body[pos:], '\n'); i != -1 {
+			end = pos + i + 1
+		}
+		line := bytes.TrimRight(body[pos:end], " \t\r\n")
+
+		if bytes.HasPrefix(line, dash) {
+			rest := line[len(dash):]
+			isClose := bytes.Equal(rest, []byte("--"))
+			if len(rest) == 0 || isClose {
+				if start != -1 {
+					content := bytes.TrimSuffix(body[start:pos], []byte("\n"))
+					content = bytes.TrimSuffix(content, []byte("\r"))
+					part, err := parseEntity(content)
+					if err != nil {
+						return nil, err
+					}
+					parts = append(parts, part)
+				}
+				start = end
+				closed = isClose
+			}
+		}
+		pos = end
+	}
+
+	if start == -1 {
+		return nil, fmt.Errorf("mime: boundary %q not found", boundary)
+	} else if !closed {
+		return nil, fmt.Errorf("mime: missing close-delimiter for boundary %q", boundary)
+	}
+
+	return parts, nil
+}
+
+// Get returns the unfolded body of the first header field
+// matching name, or an empty string if there is none
+func (e *Entity) Get(name string) string {
+	for _, h := range e.Headers {
+		if strings.EqualFold(h.Name(), name) {
+			return header.Value(h)
+		}
+	}
+	return ""
+}
+
+// ContentType returns the lower case media type and parameters. If Content-Type
+// is missing or invalid, the default 'text/plain; charset=us-ascii' is returned
+func (e *Entity) ContentType() (mediatype string, params map[string]string) {
+	mediatype, params, err := stdmime.ParseMediaType(e.Get("Content-Type"))
+	if err != nil {
+		return "text/plain", map[string]string{"charset": "us-ascii"}
+	}
+	return mediatype, params
+}
+
+// Parts returns the body parts of a multipart entity, or nil otherwise
+func (e *Entity) Parts() []*Entity {
+	if mb, ok := e.Body.(*multipartBody); ok {
+		return mb.parts
+	}
+	return nil
+}
+
+// Content returns body decoded according to its Content-Transfer-Encoding
+func (e *Entity) Content() ([]byte, error) {
+	body := []byte(e.Body.String())
+	cte := strings.ToLower(e.Get("Content-Transfer-Encoding"))
+	switch cte {
+	case "", "7bit", "8bit", "binary":
+		return body, nil
+	case "base64":
+		clean := bytes.Map(func(r rune) rune {
+			if r == '\r' || r == '\n' || r == ' ' || r == '\t' {
+				return -1
+			}
+			return r
+		}, body)
+		dec := make([]byte, base64.StdEncoding.DecodedLen(len(clean)))
+		n, err := base64.StdEncoding.Decode(dec, clean)
+		if err != nil {
+			return nil, fmt.Errorf("mime: base64: %w", err)
+		}
+		return dec[:n], nil
+	case "quoted-printable":
+		dec, err := io.ReadAll(quotedprintable.NewReader(bytes.NewReader(body)))
+		if err != nil {
+			return nil, fmt.Errorf("mime: quoted-printable: %w", err)
+		}
+		return dec, nil
+	}
+
+	return nil, fmt.Errorf("mime: unknown Content-Transfer-Encoding %q", cte)
+}
+
+// Text returns body decoded as per Content, converted to UTF-8 from
+// the charset parameter of Content-Type, see charset.Lookup. If the
+// parameter is missing, or is us-ascii or utf-8 but the content is not,
+// the charset is detected instead, see charset.Detect
+func (e *Entity) Text() (string, error) {
+	b, err := e.Content()
+	if err != nil {
+		return "", err
+	}
+	mediatype, params := e.ContentType()
+	cs := params["charset"]
+	if cs == "" || (charset.IsUTF8(cs) && !utf8.Valid(b)) {
+		cs = charset.Detect(b, mediatype)
+	}
+	dec, err := charset.Decode(cs, b)
+	if err != nil {
+		return "", fmt.Errorf("mime: %w", err)
+	}
+	return string(dec), nil
+}
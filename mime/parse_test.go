@@ -0,0 +1,143 @@
+package mime_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadEntity(t *testing.T) {
+	raw := "From: <a@a.com>\r\n" +
+		"Subject: foo\r\n" +
+		" bar\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		"hello world"
+	e, err := mime.ReadEntity(strings.NewReader(raw))
+	assert.NoError(t, err)
+	assert.Len(t, e.Headers, 3)
+	assert.Equal(t, header.Field{FieldName: "Subject", Value: "foo bar"}, e.Headers[1])
+	assert.Equal(t, "foo bar", e.Get("subject"))
+	assert.Equal(t, "", e.Get("To"))
+	mediatype, params := e.ContentType()
+	assert.Equal(t, "text/plain", mediatype)
+	assert.Equal(t, "utf-8", params["charset"])
+	assert.Equal(t, "hello world", e.Body.String())
+	assert.Nil(t, e.Parts())
+
+	// bare LF, no body
+	e, err = mime.ReadEntity(strings.NewReader("To: <b@b.com>\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "<b@b.com>", e.Get("To"))
+	assert.Equal(t, "", e.Body.String())
+
+	// malformed
+	_, err = mime.ReadEntity(strings.NewReader("no colon\r\n\r\n"))
+	assert.Error(t, err, "missing colon")
+	_, err = mime.ReadEntity(strings.NewReader(" continuation\r\n\r\n"))
+	assert.Error(t, err, "continuation without field")
+}
+
+func TestReadEntityMultipart(t *testing.T) {
+	raw := "Content-Type: multipart/mixed; boundary=outer\r\n" +
+		"\r\n" +
+		"preamble\r\n" +
+		"--outer\r\n" +
+		"Content-Type: multipart/alternative; boundary=\"inner\"\r\n" +
+		"\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"foo bar\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/html\r\n" +
+		"\r\n" +
+		"<b>foo bar</b>\r\n" +
+		"--inner--\r\n" +
+		"--outer\r\n" +
+		"Content-Type: application/octet-stream\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		"Zm9v\r\n" +
+		"YmFy\r\n" +
+		"--outer--\r\n" +
+		"epilogue"
+	e, err := mime.ReadEntity(strings.NewReader(raw))
+	assert.NoError(t, err)
+	parts := e.Parts()
+	assert.Len(t, parts, 2)
+
+	alt := parts[0].Parts()
+	assert.Len(t, alt, 2)
+	assert.Equal(t, "foo bar", alt[0].Body.String())
+	assert.Equal(t, "<b>foo bar</b>", alt[1].Body.String())
+
+	content, err := parts[1].Content()
+	assert.NoError(t, err)
+	assert.Equal(t, "foobar", string(content))
+
+	// round trip
+	again, err := mime.ReadEntity(strings.NewReader(e.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, e.String(), again.String())
+
+	// missing close-delimiter
+	_, err = mime.ReadEntity(strings.NewReader("Content-Type: multipart/mixed; boundary=b\r\n\r\n--b\r\n\r\nfoo"))
+	assert.Error(t, err)
+}
+
+func TestEntityContent(t *testing.T) {
+	for _, c := range []struct {
+		cte  string
+		body string
+		want string
+	}{
+		{"", "foo", "foo"},
+		{"7bit", "foo", "foo"},
+		{"BASE64", "Zm9v\r\nYmFy", "foobar"},
+		{"quoted-printable", "caf=C3=A9 =\r\nau lait", "café au lait"},
+	} {
+		e := mime.NewEntity([]header.Header{header.NewContentTransferEncoding(c.cte)}, c.body)
+		got, err := e.Content()
+		assert.NoError(t, err, c.cte)
+		assert.Equal(t, c.want, string(got), c.cte)
+	}
+
+	e := mime.NewEntity([]header.Header{header.NewContentTransferEncoding("x-unknown")}, "foo")
+	_, err := e.Content()
+	assert.Error(t, err)
+}
+
+func TestEntityText(t *testing.T) {
+	for _, c := range []struct {
+		ct   string
+		cte  string
+		body string
+		want string
+	}{
+		{"text/plain", "", "caf\xe9", "café"},
+		{"text/plain; charset=us-ascii", "8bit", "\x83e\x83X\x83g\x82\xc5\x82\xb7", "テストです"},
+		{"text/plain; charset=utf-8", "8bit", "\xf0\xd2\xc9\xd7\xc5\xd4, \xcd\xc9\xd2", "Привет, мир"},
+		{"text/html", "", "<meta charset=koi8-r>\xf0\xd2\xc9\xd7\xc5\xd4", "<meta charset=koi8-r>Привет"},
+		{"text/plain; charset=utf-8", "base64", "Y2Fmw6k=", "café"},
+		{"text/plain; charset=iso-8859-1", "quoted-printable", "caf=E9", "café"},
+		{"text/plain; charset=windows-1252", "8bit", "\x80", "€"},
+		{"text/plain; charset=Shift_JIS", "base64", "g2WDWINn", "テスト"},
+		{"text/plain; charset=gb2312", "8bit", "\xd6\xd0\xce\xc4", "中文"},
+		{"text/plain; charset=koi8-r", "8bit", "\xf0\xd2\xc9\xd7\xc5\xd4", "Привет"},
+	} {
+		e := mime.NewEntity([]header.Header{
+			header.Field{FieldName: "Content-Type", Value: c.ct},
+			header.NewContentTransferEncoding(c.cte)}, c.body)
+		got, err := e.Text()
+		assert.NoError(t, err, c.ct)
+		assert.Equal(t, c.want, got, c.ct)
+	}
+
+	e := mime.NewEntity([]header.Header{header.Field{FieldName: "Content-Type", Value: "text/plain; charset=x-unknown"}}, "foo")
+	_, err := e.Text()
+	assert.Error(t, err)
+}
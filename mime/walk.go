@@ -0,0 +1,45 @@
+package mime
+
+import "errors"
+
+// SkipPart is used as a return value from WalkFunc to indicate
+// the descendants of the entity in the call are to be skipped
+var SkipPart = errors.New("skip this part")
+
+// SkipAll is used as a return value from WalkFunc to indicate
+// all remaining entities are to be skipped, Walk then returns nil
+var SkipAll = errors.New("skip everything")
+
+// WalkFunc is the type of function called by Walk to visit each entity
+type WalkFunc func(e *Entity) error
+
+// Walk calls fn for e and each of its descendants in depth first order,
+// including the parts of multipart entities and the message embedded in
+// message/rfc822 entities. If fn returns SkipPart, descendants of that
+// entity are skipped, if it returns SkipAll the walk stops, and any
+// other error stops the walk and is returned
+func (e *Entity) Walk(fn WalkFunc) error {
+	if err := e.walk(fn); err != SkipAll {
+		return err
+	}
+	return nil
+}
+
+func (e *Entity) walk(fn WalkFunc) error {
+	err := fn(e)
+	if err == SkipPart {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if inner := e.Embedded(); inner != nil {
+		return inner.walk(fn)
+	}
+	for _, p := range e.Parts() {
+		if err := p.walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
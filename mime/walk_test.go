@@ -0,0 +1,77 @@
+package mime_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	leaf := func(ct string) *mime.Entity {
+		return mime.NewEntity([]header.Header{header.NewContentType(ct, nil)}, "")
+	}
+	inner := mime.NewMultipartAlternative(nil, []*mime.Entity{leaf("text/plain"), leaf("text/html")})
+	root := mime.NewMultipartMixed(nil, []*mime.Entity{
+		inner,
+		mime.NewMessageRFC822(leaf("text/calendar")),
+		leaf("image/png"),
+	})
+
+	var got []string
+	err := root.Walk(func(e *mime.Entity) error {
+		mediatype, _ := e.ContentType()
+		got = append(got, mediatype)
+		return nil
+	})
+	assert.NoError(t, err)
+	want := []string{
+		"multipart/mixed",
+		"multipart/alternative", "text/plain", "text/html",
+		"message/rfc822", "text/calendar",
+		"image/png",
+	}
+	assert.Equal(t, want, got)
+
+	// skip
+	got = nil
+	err = root.Walk(func(e *mime.Entity) error {
+		mediatype, _ := e.ContentType()
+		got = append(got, mediatype)
+		if mediatype == "multipart/alternative" || mediatype == "message/rfc822" {
+			return mime.SkipPart
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"multipart/mixed", "multipart/alternative", "message/rfc822", "image/png"}, got)
+
+	// skip all
+	got = nil
+	err = root.Walk(func(e *mime.Entity) error {
+		mediatype, _ := e.ContentType()
+		got = append(got, mediatype)
+		if mediatype == "text/html" {
+			return mime.SkipAll
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"multipart/mixed", "multipart/alternative", "text/plain", "text/html"}, got)
+
+	// stop
+	stop := errors.New("stop")
+	got = nil
+	err = root.Walk(func(e *mime.Entity) error {
+		mediatype, _ := e.ContentType()
+		got = append(got, mediatype)
+		if mediatype == "text/plain" {
+			return stop
+		}
+		return nil
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, []string{"multipart/mixed", "multipart/alternative", "text/plain"}, got)
+}
@@ -0,0 +1,323 @@
+package goemail
+
+import (
+	"errors"
+	"fmt"
+	stdmime "mime"
+	"net/mail"
+	"sort"
+	"strings"
+
+	"github.com/jimtsao/go-email/charset"
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+)
+
+// ReplyOptions configures the reply created by Reply
+type ReplyOptions struct {
+	From  string // replying address, excluded from reply-all recipients
+	All   bool   // reply to all original recipients, not just the author
+	Body  string // reply text, placed above any quoted original
+	Quote bool   // quote original text body beneath reply
+}
+
+// ForwardOptions configures the message created by Forward
+type ForwardOptions struct {
+	From         string
+	To           string // accepts comma-separated list
+	Body         string // text placed above forwarded message
+	AsAttachment bool   // attach original as message/rfc822 rather than inline
+}
+
+// Reply creates a reply to a parsed message, see mime.ReadEntity
+//
+// Recipients follow RFC 5322 section 3.6.3, replying to Reply-To if
+// present, otherwise From. Reply-all additionally copies the original
+// To and Cc recipients, excluding the replying address.
+//
+// Threading follows RFC 5322 section 3.6.4, In-Reply-To contains the
+// original Message-ID and References extends the original References
+func Reply(original *mime.Entity, opts ReplyOptions) (*Email, error) {
+	self, err := parseAddresses(opts.From)
+	if err != nil {
+		return nil, fmt.Errorf("reply: from: %w", err)
+	}
+
+	// recipients
+	authors := original.Get("Reply-To")
+	if authors == "" {
+		authors = original.Get("From")
+	}
+	to, err := parseAddresses(authors)
+	if err != nil {
+		return nil, fmt.Errorf("reply: original author: %w", err)
+	}
+
+	// replying to our own message goes to the original recipients
+	if len(excludeAddresses(to, self)) == 0 {
+		if to, err = parseAddresses(original.Get("To")); err != nil {
+			return nil, fmt.Errorf("reply: original recipients: %w", err)
+		}
+	}
+
+	var cc []*mail.Address
+	if opts.All {
+		for _, field := range []string{"To", "Cc"} {
+			addrs, err := parseAddresses(original.Get(field))
+			if err != nil {
+				return nil, fmt.Errorf("reply: original %s: %w", field, err)
+			}
+			cc = append(cc, addrs...)
+		}
+	}
+
+	to = excludeAddresses(to, self)
+	cc = excludeAddresses(cc, append(self, to...))
+	if len(to) == 0 {
+		return nil, errors.New("reply: no recipients")
+	}
+
+	e := New()
+	e.AutoDate = true
+	e.From = opts.From
+	e.To = joinAddresses(to)
+	e.Cc = joinAddresses(cc)
+	e.Subject = prefixSubject("Re: ", decodeHeader(original.Get("Subject")), "re:")
+	e.Body = opts.Body
+
+	// threading
+	if ids := header.ParseMsgIDs(original.Get("Message-ID")); len(ids) > 0 {
+		id := ids[0]
+		e.AddHeader(header.InReplyTo{id})
+		refs := header.ParseMsgIDs(original.Get("References"))
+		if parents := header.ParseMsgIDs(original.Get("In-Reply-To")); len(refs) == 0 && len(parents) == 1 {
+			refs = parents
+		}
+		e.AddHeader(header.References(append(refs, id)))
+	}
+
+	// quote original
+	if opts.Quote {
+		if text, ok := textBody(original); ok {
+			from := decodeHeader(original.Get("From"))
+			if addr, err := mail.ParseAddress(original.Get("From")); err == nil {
+				from = addr.Address
+				if addr.Name != "" {
+					from = fmt.Sprintf("%s <%s>", addr.Name, addr.Address)
+				}
+			}
+
+			sb := strings.Builder{}
+			if e.Body != "" {
+				sb.WriteString(e.Body + "\r\n\r\n")
+			}
+			sb.WriteString(fmt.Sprintf("On %s, %s wrote:\r\n", original.Get("Date"), from))
+			sb.WriteString(quote(text))
+			e.Body = sb.String()
+		}
+	}
+
+	return e, nil
+}
+
+// Forward creates a message forwarding a parsed message, see mime.ReadEntity
+//
+// Inline forwarding quotes the original headers and text body, carrying
+// over any attachments. Otherwise original is attached as message/rfc822
+func Forward(original *mime.Entity, opts ForwardOptions) (*Email, error) {
+	if opts.To == "" {
+		return nil, errors.New("forward: no recipients")
+	}
+
+	subject := decodeHeader(original.Get("Subject"))
+	e := New()
+	e.AutoDate = true
+	e.From = opts.From
+	e.To = opts.To
+	e.Subject = prefixSubject("Fwd: ", subject, "fwd:", "fw:")
+	e.Body = opts.Body
+
+	if opts.AsAttachment {
+		filename := "forwarded message.eml"
+		if subject != "" {
+			filename = subject + ".eml"
+		}
+		e.Attachments = []*Attachment{{Filename: filename, Message: original}}
+		return e, nil
+	}
+
+	// quoted headers
+	sb := strings.Builder{}
+	if e.Body != "" {
+		sb.WriteString(e.Body + "\r\n\r\n")
+	}
+	sb.WriteString("---------- Forwarded message ----------\r\n")
+	for _, name := range []string{"From", "Date", "Subject", "To", "Cc"} {
+		if v := original.Get(name); v != "" {
+			sb.WriteString(fmt.Sprintf("%s: %s\r\n", name, decodeHeader(v)))
+		}
+	}
+	sb.WriteString("\r\n")
+	text, _ := textBody(original)
+	sb.WriteString(text)
+	e.Body = sb.String()
+
+	// attachments
+	var err error
+	walk(original, func(part *mime.Entity) {
+		if err != nil {
+			return
+		}
+		var att *Attachment
+		if att, err = attachment(part); att != nil {
+			e.Attachments = append(e.Attachments, att)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("forward: %w", err)
+	}
+
+	return e, nil
+}
+
+// walk calls fn for each leaf entity in depth first order,
+// message/rfc822 entities are treated as a leaf
+func walk(e *mime.Entity, fn func(*mime.Entity)) {
+	e.Walk(func(part *mime.Entity) error {
+		if part.Embedded() != nil {
+			fn(part)
+			return mime.SkipPart
+		}
+		if part.Parts() == nil {
+			fn(part)
+		}
+		return nil
+	})
+}
+
+// textBody returns first text/plain part that is not an attachment
+func textBody(e *mime.Entity) (string, bool) {
+	var text string
+	var found bool
+	walk(e, func(part *mime.Entity) {
+		if found {
+			return
+		}
+		disposition, _, _ := stdmime.ParseMediaType(part.Get("Content-Disposition"))
+		if mediatype, _ := part.ContentType(); mediatype != "text/plain" || disposition == "attachment" {
+			return
+		}
+		if s, err := part.Text(); err == nil {
+			text, found = s, true
+		}
+	})
+	return text, found
+}
+
+// attachment returns part as an Attachment if it has a
+// filename or attachment disposition, otherwise nil
+func attachment(part *mime.Entity) (*Attachment, error) {
+	disposition, dparams, _ := stdmime.ParseMediaType(part.Get("Content-Disposition"))
+	mediatype, cparams := part.ContentType()
+	filename := dparams["filename"]
+	if filename == "" {
+		filename = cparams["name"]
+	}
+	if disposition != "attachment" && filename == "" {
+		return nil, nil
+	}
+
+	data, err := part.Content()
+	if err != nil {
+		return nil, err
+	}
+
+	// original parameters such as charset are retained, in sorted order
+	var params []header.MIMEParam
+	names := make([]string, 0, len(cparams))
+	for name := range cparams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		params = append(params, header.MIMEParam{Attribute: name, Value: cparams[name]})
+	}
+
+	return &Attachment{
+		Inline:      disposition == "inline",
+		Filename:    filename,
+		ContentID:   part.Get("Content-ID"),
+		ContentType: mediatype,
+		Params:      params,
+		Data:        data,
+	}, nil
+}
+
+func quote(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\r\n"), "\n")
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" || line[0] == '>' {
+			lines[i] = ">" + line
+		} else {
+			lines[i] = "> " + line
+		}
+	}
+	return strings.Join(lines, "\r\n")
+}
+
+// prefixSubject adds prefix unless subject already begins with
+// prefix or any of its equivalents, compared case insensitively
+func prefixSubject(prefix string, subject string, existing ...string) string {
+	lower := strings.ToLower(subject)
+	for _, p := range existing {
+		if strings.HasPrefix(lower, p) {
+			return subject
+		}
+	}
+	if subject == "" {
+		return strings.TrimSpace(prefix)
+	}
+	return prefix + subject
+}
+
+func decodeHeader(s string) string {
+	if d, err := charset.DecodeHeader(s); err == nil {
+		return d
+	}
+	return s
+}
+
+func parseAddresses(s string) ([]*mail.Address, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	return header.ParseAddressList(s)
+}
+
+// excludeAddresses returns addrs with any address in exclude or
+// duplicates removed, compared case insensitively
+func excludeAddresses(addrs []*mail.Address, exclude []*mail.Address) []*mail.Address {
+	seen := map[string]bool{}
+	for _, a := range exclude {
+		seen[strings.ToLower(a.Address)] = true
+	}
+
+	var res []*mail.Address
+	for _, a := range addrs {
+		key := strings.ToLower(a.Address)
+		if !seen[key] {
+			seen[key] = true
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+func joinAddresses(addrs []*mail.Address) string {
+	s := make([]string, len(addrs))
+	for i, a := range addrs {
+		s[i] = a.String()
+	}
+	return strings.Join(s, ", ")
+}
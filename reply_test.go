@@ -0,0 +1,148 @@
+package goemail_test
+
+import (
+	"strings"
+	"testing"
+
+	goemail "github.com/jimtsao/go-email"
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+	"github.com/stretchr/testify/assert"
+)
+
+func original(t *testing.T, subject string) *mime.Entity {
+	raw := "From: Alice <alice@a.com>\r\n" +
+		"To: Bob <bob@b.com>, carol@c.com\r\n" +
+		"Cc: dave@d.com, BOB@b.com\r\n" +
+		"Date: Tue, 3 Apr 1990 05:30:15 +1000\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Message-ID: <2@a.com>\r\n" +
+		"References: <0@a.com>\r\n <1@a.com>\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=b\r\n" +
+		"\r\n" +
+		"--b\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		"hello\r\n" +
+		"\r\n" +
+		"> earlier\r\n" +
+		"--b\r\n" +
+		"Content-Type: text/plain; charset=iso-8859-1; format=flowed\r\n" +
+		"Content-Disposition: attachment; filename=notes.txt\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		"bm90ZXM=\r\n" +
+		"--b--"
+	e, err := mime.ReadEntity(strings.NewReader(raw))
+	assert.NoError(t, err)
+	return e
+}
+
+func TestReply(t *testing.T) {
+	orig := original(t, "=?utf-8?q?caf=C3=A9?=")
+
+	// reply to author
+	r, err := goemail.Reply(orig, goemail.ReplyOptions{From: "bob@b.com", Body: "hi"})
+	assert.NoError(t, err)
+	assert.Equal(t, "\"Alice\" <alice@a.com>", r.To)
+	assert.Equal(t, "", r.Cc)
+	assert.Equal(t, "Re: café", r.Subject)
+	assert.Equal(t, "hi", r.Body)
+	raw := r.Raw()
+	assert.Contains(t, raw, "In-Reply-To: <2@a.com>\r\n")
+	assert.Contains(t, raw, "References: <0@a.com> <1@a.com> <2@a.com>\r\n")
+	assert.Empty(t, r.Validate())
+
+	// reply all excludes self and duplicates
+	r, err = goemail.Reply(orig, goemail.ReplyOptions{From: "Bob <bob@b.com>", All: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "\"Alice\" <alice@a.com>", r.To)
+	assert.Equal(t, "<carol@c.com>, <dave@d.com>", r.Cc)
+
+	// reply to own message goes to original recipients
+	r, err = goemail.Reply(orig, goemail.ReplyOptions{From: "alice@a.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "\"Bob\" <bob@b.com>, <carol@c.com>", r.To)
+
+	// quote original, no duplicate prefix
+	orig = original(t, "RE: foo")
+	r, err = goemail.Reply(orig, goemail.ReplyOptions{From: "bob@b.com", Body: "hi", Quote: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "RE: foo", r.Subject)
+	want := "hi\r\n" +
+		"\r\n" +
+		"On Tue, 3 Apr 1990 05:30:15 +1000, Alice <alice@a.com> wrote:\r\n" +
+		"> hello\r\n" +
+		">\r\n" +
+		">> earlier"
+	assert.Equal(t, want, r.Body)
+}
+
+func TestForward(t *testing.T) {
+	orig := original(t, "foo")
+
+	// inline
+	f, err := goemail.Forward(orig, goemail.ForwardOptions{From: "bob@b.com", To: "eve@e.com", Body: "fyi"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Fwd: foo", f.Subject)
+	want := "fyi\r\n" +
+		"\r\n" +
+		"---------- Forwarded message ----------\r\n" +
+		"From: Alice <alice@a.com>\r\n" +
+		"Date: Tue, 3 Apr 1990 05:30:15 +1000\r\n" +
+		"Subject: foo\r\n" +
+		"To: Bob <bob@b.com>, carol@c.com\r\n" +
+		"Cc: dave@d.com, BOB@b.com\r\n" +
+		"\r\n" +
+		"hello\r\n" +
+		"\r\n" +
+		"> earlier"
+	assert.Equal(t, want, f.Body)
+	assert.Len(t, f.Attachments, 1)
+	assert.Equal(t, "notes.txt", f.Attachments[0].Filename)
+	assert.Equal(t, "notes", string(f.Attachments[0].Data))
+	assert.Equal(t, header.NewMIMEParams("charset", "iso-8859-1", "format", "flowed"), f.Attachments[0].Params)
+	assert.Empty(t, f.Validate())
+	f.From, f.To = "bob@b.com", "eve@e.com"
+	assert.Contains(t, f.Raw(), "Content-Type: text/plain; charset=iso-8859-1; format=flowed\r\n"+
+		"Content-Disposition: attachment; filename=notes.txt\r\n")
+
+	// as attachment
+	f, err = goemail.Forward(orig, goemail.ForwardOptions{From: "bob@b.com", To: "eve@e.com", AsAttachment: true})
+	assert.NoError(t, err)
+	raw := f.Raw()
+	assert.Contains(t, raw, "Content-Type: message/rfc822\r\n"+
+		"Content-Disposition: attachment; filename=foo.eml\r\n"+
+		"Content-Transfer-Encoding: 7bit\r\n"+
+		"\r\n"+
+		"From: Alice <alice@a.com>\r\n")
+	parsed, err := mime.ReadEntity(strings.NewReader(raw))
+	assert.NoError(t, err)
+	if assert.NotNil(t, parsed.Embedded()) {
+		assert.Equal(t, "foo", parsed.Embedded().Get("Subject"))
+	}
+
+	// non-ascii original is not base64 encoded
+	f, err = goemail.Forward(original(t, "café"), goemail.ForwardOptions{From: "bob@b.com", To: "eve@e.com", AsAttachment: true})
+	assert.NoError(t, err)
+	assert.Contains(t, f.Raw(), "Content-Type: message/rfc822\r\n"+
+		"Content-Disposition: attachment; filename*=utf-8''caf%C3%A9.eml\r\n"+
+		"Content-Transfer-Encoding: 8bit\r\n")
+
+	// empty subject
+	f, err = goemail.Forward(original(t, ""), goemail.ForwardOptions{To: "eve@e.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Fwd:", f.Subject)
+	r, err := goemail.Reply(original(t, ""), goemail.ReplyOptions{From: "bob@b.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Re:", r.Subject)
+
+	// no duplicate prefix
+	f, err = goemail.Forward(original(t, "Fw: foo"), goemail.ForwardOptions{To: "eve@e.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Fw: foo", f.Subject)
+
+	_, err = goemail.Forward(orig, goemail.ForwardOptions{})
+	assert.Error(t, err, "no recipients")
+}
@@ -0,0 +1,207 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+)
+
+// FeedbackType indicates the type of feedback being reported
+type FeedbackType string
+
+const (
+	FeedbackAbuse       FeedbackType = "abuse"        // unsolicited email or other abuse
+	FeedbackAuthFailure FeedbackType = "auth-failure" // failed authentication check
+	FeedbackFraud       FeedbackType = "fraud"        // fraudulent or phishing
+	FeedbackNotSpam     FeedbackType = "not-spam"     // incorrectly tagged as spam
+	FeedbackOther       FeedbackType = "other"        // any other feedback
+	FeedbackVirus       FeedbackType = "virus"        // report of virus or malware
+)
+
+// FeedbackReport represents the message/feedback-report body of an
+// Abuse Reporting Format message (RFC 5965), typically sent by mailbox
+// providers as part of a feedback loop
+//
+// Version defaults to 1 and UserAgent to "go-email" when output if unset
+//
+// Syntax:
+//
+//	feedback-type        = "Feedback-Type:" [CFWS] token [CFWS] CRLF
+//	user-agent           = "User-Agent:" [ CFWS ] product *( RWS ( product / comment ) ) [ CFWS ] CRLF
+//	version              = "Version:" [CFWS] %x31 [CFWS] CRLF
+//	arrival-date         = "Arrival-Date:" [CFWS] date-time CRLF
+//	incidents            = "Incidents:" [CFWS] 1*DIGIT [CFWS] CRLF
+//	original-envelope-id = "Original-Envelope-Id:" [CFWS] envelope-id [CFWS] CRLF
+//	original-mail-from   = "Original-Mail-From:" [CFWS] reverse-path [CFWS] CRLF
+//	original-rcpt-to     = "Original-Rcpt-To:" [CFWS] forward-path [CFWS] CRLF
+//	reported-domain      = "Reported-Domain:" [CFWS] domain [CFWS] CRLF
+//	reported-uri         = "Reported-URI:" [CFWS] URI [CFWS] CRLF
+//	reporting-mta        = "Reporting-MTA:" [CFWS] mta-name-type [CFWS] ";" [CFWS] mta-name [CFWS] CRLF
+//	source-ip            = "Source-IP:" [CFWS] ( IPv4address / IPv6address ) [CFWS] CRLF
+type FeedbackReport struct {
+	FeedbackType          FeedbackType
+	UserAgent             string
+	Version               int
+	ArrivalDate           time.Time
+	Incidents             int
+	OriginalEnvelopeID    string
+	OriginalMailFrom      string
+	OriginalRcptTo        []string
+	ReportedDomain        []string
+	ReportedURI           []string
+	ReportingMTA          string
+	SourceIP              net.IP
+	AuthenticationResults []string
+}
+
+// Validate checks required fields are present and well formed
+func (r *FeedbackReport) Validate() error {
+	switch r.FeedbackType {
+	case FeedbackAbuse, FeedbackAuthFailure, FeedbackFraud, FeedbackNotSpam, FeedbackOther, FeedbackVirus:
+	case "":
+		return errors.New("feedback-report: missing Feedback-Type")
+	default:
+		// extension types are permitted provided they are tokens
+		if strings.ContainsAny(string(r.FeedbackType), " \t()<>@,;:\\\"/[]?=") {
+			return fmt.Errorf("feedback-report: invalid Feedback-Type %q", r.FeedbackType)
+		}
+	}
+	if r.Version != 0 && r.Version != 1 {
+		return fmt.Errorf("feedback-report: unsupported Version %d", r.Version)
+	}
+	if r.Incidents < 0 {
+		return fmt.Errorf("feedback-report: invalid Incidents %d", r.Incidents)
+	}
+	return nil
+}
+
+// Entity returns the message/feedback-report entity
+func (r *FeedbackReport) Entity() *mime.Entity {
+	ua := r.UserAgent
+	if ua == "" {
+		ua = "go-email"
+	}
+	version := r.Version
+	if version == 0 {
+		version = 1
+	}
+
+	var f fields
+	f.add("Feedback-Type", string(r.FeedbackType))
+	f.add("User-Agent", ua)
+	f.add("Version", strconv.Itoa(version))
+	f.addDate("Arrival-Date", r.ArrivalDate)
+	if r.Incidents > 0 {
+		f.add("Incidents", strconv.Itoa(r.Incidents))
+	}
+	f.add("Original-Envelope-Id", r.OriginalEnvelopeID)
+	f.add("Original-Mail-From", angleAddr(r.OriginalMailFrom))
+	for _, rcpt := range r.OriginalRcptTo {
+		f.add("Original-Rcpt-To", angleAddr(rcpt))
+	}
+	for _, d := range r.ReportedDomain {
+		f.add("Reported-Domain", d)
+	}
+	for _, u := range r.ReportedURI {
+		f.add("Reported-URI", u)
+	}
+	f.addTyped("Reporting-MTA", "dns", r.ReportingMTA)
+	if r.SourceIP != nil {
+		f.add("Source-IP", r.SourceIP.String())
+	}
+	for _, ar := range r.AuthenticationResults {
+		f.add("Authentication-Results", ar)
+	}
+
+	return mime.NewEntity([]header.Header{
+		header.NewContentType("message/feedback-report", nil),
+	}, encodeBlocks(f))
+}
+
+// NewFeedbackReport returns a multipart/report; report-type=feedback-report
+// entity consisting of human readable text, the feedback report and the
+// original message, or only its headers if headersOnly is set
+func NewFeedbackReport(headers []header.Header, text string, report *FeedbackReport, original *mime.Entity, headersOnly bool) *mime.Entity {
+	return NewFeedbackReportFrom(nil, headers, text, report, original, headersOnly)
+}
+
+// NewFeedbackReportFrom is NewFeedbackReport, using src for the boundary
+func NewFeedbackReportFrom(src *header.Source, headers []header.Header, text string, report *FeedbackReport, original *mime.Entity, headersOnly bool) *mime.Entity {
+	return newReport(src, "feedback-report", headers, text, report.Entity(), original, headersOnly)
+}
+
+// ParseFeedbackReport parses the first message/feedback-report part of e,
+// returning ErrNoReport if there is none. Headers of the reported message
+// are available via ParseOriginalMessage
+func ParseFeedbackReport(e *mime.Entity) (*FeedbackReport, error) {
+	part := findPart(e, "message/feedback-report")
+	if part == nil {
+		return nil, ErrNoReport
+	}
+	content, err := part.Content()
+	if err != nil {
+		return nil, fmt.Errorf("feedback-report: %w", err)
+	}
+	blocks, err := parseBlocks(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("feedback-report: %w", err)
+	}
+	if len(blocks) == 0 {
+		return nil, errors.New("feedback-report: empty report")
+	}
+
+	f := blocks[0]
+	r := &FeedbackReport{
+		FeedbackType:       FeedbackType(strings.ToLower(f.get("Feedback-Type"))),
+		UserAgent:          f.get("User-Agent"),
+		ArrivalDate:        f.getDate("Arrival-Date"),
+		OriginalEnvelopeID: f.get("Original-Envelope-Id"),
+		OriginalMailFrom:   strings.Trim(f.get("Original-Mail-From"), "<>"),
+		ReportingMTA:       f.get("Reporting-MTA"),
+		SourceIP:           net.ParseIP(f.get("Source-IP")),
+	}
+
+	// obsolete Received-Date is equivalent to Arrival-Date
+	if r.ArrivalDate.IsZero() {
+		r.ArrivalDate = f.getDate("Received-Date")
+	}
+	if v := f.get("Version"); v != "" {
+		if r.Version, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("feedback-report: invalid Version %q", v)
+		}
+	}
+	if v := f.get("Incidents"); v != "" {
+		if r.Incidents, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("feedback-report: invalid Incidents %q", v)
+		}
+	}
+	for _, h := range f {
+		v := header.Value(h)
+		switch strings.ToLower(h.Name()) {
+		case "original-rcpt-to":
+			r.OriginalRcptTo = append(r.OriginalRcptTo, strings.Trim(v, "<>"))
+		case "reported-domain":
+			r.ReportedDomain = append(r.ReportedDomain, v)
+		case "reported-uri":
+			r.ReportedURI = append(r.ReportedURI, v)
+		case "authentication-results":
+			r.AuthenticationResults = append(r.AuthenticationResults, v)
+		}
+	}
+
+	return r, nil
+}
+
+// angleAddr encloses an SMTP path in angle brackets
+func angleAddr(s string) string {
+	if s == "" || strings.HasPrefix(s, "<") {
+		return s
+	}
+	return "<" + s + ">"
+}
@@ -0,0 +1,151 @@
+package report_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+	"github.com/jimtsao/go-email/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedbackReport(t *testing.T) {
+	arrival := time.Date(2000, time.January, 2, 12, 40, 20, 0, time.UTC)
+	fr := &report.FeedbackReport{
+		FeedbackType:     report.FeedbackAbuse,
+		UserAgent:        "SomeGenerator/1.0",
+		ArrivalDate:      arrival,
+		OriginalMailFrom: "alice@a.com",
+		OriginalRcptTo:   []string{"bob@b.com"},
+		ReportedDomain:   []string{"a.com"},
+		SourceIP:         net.ParseIP("192.0.2.1"),
+	}
+	assert.NoError(t, fr.Validate())
+
+	want := "Content-Type: message/feedback-report\r\n" +
+		"\r\n" +
+		"Feedback-Type: abuse\r\n" +
+		"User-Agent: SomeGenerator/1.0\r\n" +
+		"Version: 1\r\n" +
+		"Arrival-Date: Sun, 2 Jan 2000 12:40:20 +0000\r\n" +
+		"Original-Mail-From: <alice@a.com>\r\n" +
+		"Original-Rcpt-To: <bob@b.com>\r\n" +
+		"Reported-Domain: a.com\r\n" +
+		"Source-IP: 192.0.2.1\r\n"
+	assert.Equal(t, want, fr.Entity().String())
+
+	// round trip
+	arf := report.NewFeedbackReport([]header.Header{
+		header.Address{Field: header.AddressFrom, Value: "abuse@b.com"},
+		header.Subject("FW: foo"),
+	}, "This is an email abuse report", fr, originalMessage(), false)
+	parsed, err := mime.ReadEntity(strings.NewReader(arf.String()))
+	assert.NoError(t, err)
+	_, params := parsed.ContentType()
+	assert.Equal(t, "feedback-report", params["report-type"])
+
+	got, err := report.ParseFeedbackReport(parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, report.FeedbackAbuse, got.FeedbackType)
+	assert.Equal(t, 1, got.Version)
+	assert.Equal(t, "alice@a.com", got.OriginalMailFrom)
+	assert.Equal(t, []string{"bob@b.com"}, got.OriginalRcptTo)
+	assert.True(t, arrival.Equal(got.ArrivalDate))
+	assert.True(t, fr.SourceIP.Equal(got.SourceIP))
+
+	orig, err := report.ParseOriginalMessage(parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice@a.com", orig.From)
+	assert.Equal(t, []string{"bob@b.com"}, orig.To)
+	assert.Equal(t, "foo", orig.Subject)
+	assert.Equal(t, "<1@a.com>", orig.MessageID)
+	assert.Len(t, orig.Headers, 4)
+
+	// headers only
+	arf = report.NewFeedbackReport(nil, "This is an email abuse report", fr, originalMessage(), true)
+	parsed, err = mime.ReadEntity(strings.NewReader(arf.String()))
+	assert.NoError(t, err)
+	orig, err = report.ParseOriginalMessage(parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice@a.com", orig.From)
+	assert.Equal(t, "<1@a.com>", orig.MessageID)
+
+	_, err = report.ParseOriginalMessage(originalMessage())
+	assert.ErrorIs(t, err, report.ErrNoReport)
+
+	// invalid
+	for _, r := range []*report.FeedbackReport{
+		{},
+		{FeedbackType: "not a token"},
+		{FeedbackType: report.FeedbackOther, Version: 2},
+	} {
+		assert.Error(t, r.Validate())
+	}
+
+	_, err = report.ParseFeedbackReport(originalMessage())
+	assert.ErrorIs(t, err, report.ErrNoReport)
+}
+
+func TestParseFeedbackReport(t *testing.T) {
+	// RFC 5965 appendix B.2 (abridged)
+	msg := "From: <abusedesk@example.com>\r\n" +
+		"To: <abuse@example.net>\r\n" +
+		"Subject: FW: Earn money\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/report; report-type=feedback-report;\r\n" +
+		"     boundary=\"part1_13d.2e68ed54_boundary\"\r\n" +
+		"\r\n" +
+		"--part1_13d.2e68ed54_boundary\r\n" +
+		"Content-Type: text/plain; charset=\"US-ASCII\"\r\n" +
+		"Content-Transfer-Encoding: 7bit\r\n" +
+		"\r\n" +
+		"This is an email abuse report for an email message received from IP\r\n" +
+		"192.0.2.1 on Thu, 8 Mar 2005 14:00:00 EDT.\r\n" +
+		"--part1_13d.2e68ed54_boundary\r\n" +
+		"Content-Type: message/feedback-report\r\n" +
+		"\r\n" +
+		"Feedback-Type: abuse\r\n" +
+		"User-Agent: SomeGenerator/1.0\r\n" +
+		"Version: 1\r\n" +
+		"Original-Mail-From: <somespammer@example.net>\r\n" +
+		"Original-Rcpt-To: <user@example.com>\r\n" +
+		"Received-Date: Thu, 8 Mar 2005 14:00:00 EDT\r\n" +
+		"Source-IP: 192.0.2.1\r\n" +
+		"Authentication-Results: mail.example.com;\r\n" +
+		"               spf=fail smtp.mail=somespammer@example.com\r\n" +
+		"Reported-Domain: example.net\r\n" +
+		"Reported-Uri: http://example.net/earn_money.html\r\n" +
+		"Reported-Uri: mailto:user@example.com\r\n" +
+		"Removal-Recipient: user@example.com\r\n" +
+		"\r\n" +
+		"--part1_13d.2e68ed54_boundary\r\n" +
+		"Content-Type: message/rfc822\r\n" +
+		"Content-Disposition: inline\r\n" +
+		"\r\n" +
+		"From: <somespammer@example.net>\r\n" +
+		"Subject: Earn money\r\n" +
+		"\r\n" +
+		"Spam Spam Spam\r\n" +
+		"--part1_13d.2e68ed54_boundary--\r\n"
+
+	e, err := mime.ReadEntity(strings.NewReader(msg))
+	assert.NoError(t, err)
+	got, err := report.ParseFeedbackReport(e)
+	assert.NoError(t, err)
+	assert.Equal(t, report.FeedbackAbuse, got.FeedbackType)
+	assert.Equal(t, "somespammer@example.net", got.OriginalMailFrom)
+	assert.Equal(t, []string{"user@example.com"}, got.OriginalRcptTo)
+	assert.Equal(t, []string{"http://example.net/earn_money.html", "mailto:user@example.com"}, got.ReportedURI)
+	assert.Equal(t, []string{"mail.example.com;               spf=fail smtp.mail=somespammer@example.com"}, got.AuthenticationResults)
+	assert.Equal(t, "2005-03-08T18:00:00Z", got.ArrivalDate.UTC().Format(time.RFC3339))
+	assert.Equal(t, "192.0.2.1", got.SourceIP.String())
+
+	orig, err := report.ParseOriginalMessage(e)
+	assert.NoError(t, err)
+	assert.Equal(t, "somespammer@example.net", orig.From)
+	assert.Equal(t, "Earn money", orig.Subject)
+	assert.Len(t, orig.Headers, 2)
+}
@@ -0,0 +1,196 @@
+package report
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/jimtsao/go-email/mime"
+)
+
+// Bounce describes a failed or delayed delivery to a single recipient
+type Bounce struct {
+	Recipient  string
+	Action     Action // ActionFailed or ActionDelayed
+	Status     string // RFC 3463 status code, eg 5.1.1
+	Diagnostic string
+	Standard   bool // parsed from a RFC 3464 delivery status rather than heuristics
+}
+
+// Permanent reports whether bounce is a permanent (hard) failure
+func (b Bounce) Permanent() bool {
+	return len(b.Status) > 0 && b.Status[0] == '5'
+}
+
+// status and reply codes must not be part of a longer dotted number, eg an
+// ip address such as 10.4.12.7, go regexp lacks lookaround so the delimiting
+// characters are matched
+var (
+	enhancedCode = regexp.MustCompile(`(?:^|[^\d.])([245]\.\d{1,3}\.\d{1,3})(?:[^\d.]|$)`)
+	replyCode    = regexp.MustCompile(`(?:^|[^\d.])([45])\d\d(?:[^\d.]|$)`)
+	replyStatus  = regexp.MustCompile(`(?:^|[^\d.])[245]\d\d[ -]([245]\.\d{1,3}\.\d{1,3})(?:[^\d.]|$)`)
+	addrSpec     = regexp.MustCompile(`[A-Za-z0-9.!#$%&'*+/=?^_{|}~-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)+`)
+
+	bounceSubject = regexp.MustCompile(`(?i)undeliver|undelivered|delivery (status notification|failure|has failed)|` +
+		`failure notice|mail delivery failed|returned mail|delivery problem|could not be delivered|rejected`)
+	delaySubject = regexp.MustCompile(`(?i)delayed|delay|warning: could not send|still being retried`)
+	bounceSender = regexp.MustCompile(`(?i)mailer-daemon|postmaster`)
+)
+
+// diagnostic phrases commonly found in non-standard bounces
+var phrases = []struct {
+	re     *regexp.Regexp
+	status string
+}{
+	{regexp.MustCompile(`(?i)user unknown|no such user|unknown user|does not exist|mailbox unavailable|address rejected|recipient not found`), "5.1.1"},
+	{regexp.MustCompile(`(?i)host unknown|domain not found|no mx|unrouteable`), "5.1.2"},
+	{regexp.MustCompile(`(?i)mailbox (is )?full|over quota|quota exceeded|insufficient storage`), "5.2.2"},
+	{regexp.MustCompile(`(?i)message (is )?too (large|big)|size limit`), "5.3.4"},
+	{regexp.MustCompile(`(?i)spam|blocked|blacklist|policy`), "5.7.1"},
+}
+
+// ParseBounce classifies a delivery failure notification. Standard RFC 3464
+// reports are read from the delivery status, returning recipients whose
+// Action is failed or delayed. Otherwise common non-standard bounces are
+// recognised by sender, subject or the X-Failed-Recipients header and their
+// text is scanned for recipients, status codes and diagnostic phrases
+//
+// ErrNoReport is returned if e does not appear to be a bounce
+func ParseBounce(e *mime.Entity) ([]Bounce, error) {
+	ds, err := ParseDeliveryStatus(e)
+	if err == nil {
+		var bounces []Bounce
+		for _, r := range ds.Recipients {
+			if r.Action != ActionFailed && r.Action != ActionDelayed {
+				continue
+			}
+			status := r.Status
+			if !statusCode.MatchString(status) {
+				status = guessStatus(r.Diagnostic(), r.Action == ActionDelayed)
+			}
+			bounces = append(bounces, Bounce{
+				Recipient:  r.Address(),
+				Action:     r.Action,
+				Status:     status,
+				Diagnostic: r.Diagnostic(),
+				Standard:   true,
+			})
+		}
+		return bounces, nil
+	} else if err != ErrNoReport {
+		return nil, err
+	}
+
+	return parseNonStandard(e)
+}
+
+func parseNonStandard(e *mime.Entity) ([]Bounce, error) {
+	from := e.Get("From")
+	subject := e.Get("Subject")
+	failed := e.Get("X-Failed-Recipients")
+	if failed == "" && !bounceSender.MatchString(from) && !bounceSubject.MatchString(subject) {
+		return nil, ErrNoReport
+	}
+
+	text := bounceText(e)
+	delayed := delaySubject.MatchString(subject)
+	action := ActionFailed
+	if delayed {
+		action = ActionDelayed
+	}
+
+	// recipients
+	var recipients []string
+	if failed != "" {
+		for _, r := range strings.Split(failed, ",") {
+			recipients = append(recipients, strings.TrimSpace(r))
+		}
+	} else {
+		exclude := map[string]bool{}
+		for _, field := range []string{"From", "To", "Return-Path"} {
+			if addrs, err := mail.ParseAddressList(e.Get(field)); err == nil {
+				for _, a := range addrs {
+					exclude[strings.ToLower(a.Address)] = true
+				}
+			}
+		}
+		for _, a := range addrSpec.FindAllString(text, -1) {
+			if key := strings.ToLower(a); !exclude[key] {
+				exclude[key] = true
+				recipients = append(recipients, a)
+			}
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, ErrNoReport
+	}
+
+	// diagnostic line is first containing a status or reply code
+	diagnostic := ""
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if enhancedCode.MatchString(line) || replyCode.MatchString(line) {
+			diagnostic = line
+			break
+		}
+	}
+	status := guessStatus(text, delayed)
+
+	bounces := make([]Bounce, len(recipients))
+	for i, r := range recipients {
+		bounces[i] = Bounce{Recipient: r, Action: action, Status: status, Diagnostic: diagnostic}
+	}
+	return bounces, nil
+}
+
+// guessStatus derives status code from an enhanced status code following
+// an smtp reply code, any other enhanced status code, or the smtp reply code
+// class, in that order of preference. Status codes of the form x.0.0 are
+// refined by diagnostic phrases contained within text, eg "550 no such user"
+// returns 5.1.1
+func guessStatus(text string, delayed bool) string {
+	class := "5"
+	if delayed {
+		class = "4"
+	}
+
+	status := class + ".0.0"
+	if m := replyStatus.FindStringSubmatch(text); m != nil {
+		status = m[1]
+	} else if m := enhancedCode.FindStringSubmatch(text); m != nil {
+		status = m[1]
+	} else if m := replyCode.FindStringSubmatch(text); m != nil {
+		// smtp reply code class determines status class
+		status = m[1] + ".0.0"
+	}
+	if !strings.HasSuffix(status, ".0.0") {
+		return status
+	}
+
+	for _, p := range phrases {
+		if p.re.MatchString(text) {
+			return status[:1] + p.status[1:]
+		}
+	}
+	return status
+}
+
+// bounceText returns the text of all text/plain parts, excluding
+// those of the returned original message
+func bounceText(e *mime.Entity) string {
+	sb := strings.Builder{}
+	e.Walk(func(part *mime.Entity) error {
+		if part.Embedded() != nil {
+			return mime.SkipPart
+		}
+		if mediatype, _ := part.ContentType(); mediatype != "text/plain" || part.Parts() != nil {
+			return nil
+		}
+		if b, err := part.Content(); err == nil {
+			sb.Write(b)
+			sb.WriteString("\n")
+		}
+		return nil
+	})
+	return sb.String()
+}
@@ -0,0 +1,112 @@
+package report_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jimtsao/go-email/mime"
+	"github.com/jimtsao/go-email/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBounceStandard(t *testing.T) {
+	raw := "From: MAILER-DAEMON@b.com\r\n" +
+		"Content-Type: multipart/report; report-type=delivery-status; boundary=b\r\n" +
+		"\r\n" +
+		"--b\r\n" +
+		"\r\n" +
+		"delivery failed\r\n" +
+		"--b\r\n" +
+		"Content-Type: message/delivery-status\r\n" +
+		"\r\n" +
+		"Reporting-MTA: dns; mx.b.com\r\n" +
+		"\r\n" +
+		"Final-Recipient: rfc822; bob@b.com\r\n" +
+		"Action: Failed\r\n" +
+		"Status: 5.1.1 (user unknown)\r\n" +
+		"Diagnostic-Code: smtp; 550 5.1.1 user unknown\r\n" +
+		"\r\n" +
+		"Final-Recipient: rfc822; carol@b.com\r\n" +
+		"Action: delivered\r\n" +
+		"Status: 2.0.0\r\n" +
+		"--b--\r\n"
+	e, err := mime.ReadEntity(strings.NewReader(raw))
+	assert.NoError(t, err)
+	bounces, err := report.ParseBounce(e)
+	assert.NoError(t, err)
+	assert.Equal(t, []report.Bounce{{
+		Recipient:  "bob@b.com",
+		Action:     report.ActionFailed,
+		Status:     "5.1.1",
+		Diagnostic: "550 5.1.1 user unknown",
+		Standard:   true,
+	}}, bounces)
+	assert.True(t, bounces[0].Permanent())
+}
+
+func TestParseBounceNonStandard(t *testing.T) {
+	for _, c := range []struct {
+		desc string
+		raw  string
+		want []report.Bounce
+	}{
+		{desc: "qmail",
+			raw: "From: MAILER-DAEMON@a.com\r\n" +
+				"To: alice@a.com\r\n" +
+				"Subject: failure notice\r\n" +
+				"\r\n" +
+				"Hi. This is the qmail-send program at a.com.\r\n" +
+				"I'm afraid I wasn't able to deliver your message to the following addresses.\r\n" +
+				"\r\n" +
+				"<bob@b.com>:\r\n" +
+				"Remote host said: 550 No such user here\r\n",
+			want: []report.Bounce{{Recipient: "bob@b.com", Action: report.ActionFailed,
+				Status: "5.1.1", Diagnostic: "Remote host said: 550 No such user here"}}},
+		{desc: "ip address",
+			raw: "From: MAILER-DAEMON@a.com\r\n" +
+				"Subject: failure notice\r\n" +
+				"\r\n" +
+				"<bob@b.com>:\r\n" +
+				"Connected to 10.4.12.7 but sender was rejected.\r\n" +
+				"Remote host said: 550 no such user\r\n",
+			want: []report.Bounce{{Recipient: "bob@b.com", Action: report.ActionFailed,
+				Status: "5.1.1", Diagnostic: "Remote host said: 550 no such user"}}},
+		{desc: "status following reply code",
+			raw: "From: MAILER-DAEMON@a.com\r\n" +
+				"Subject: Undelivered Mail Returned to Sender\r\n" +
+				"\r\n" +
+				"Earlier attempts deferred (4.4.1 connection timed out)\r\n" +
+				"<bob@b.com>: host mx.b.com said: 554 5.7.1 message rejected\r\n",
+			want: []report.Bounce{{Recipient: "bob@b.com", Action: report.ActionFailed,
+				Status: "5.7.1", Diagnostic: "Earlier attempts deferred (4.4.1 connection timed out)"}}},
+		{desc: "exim",
+			raw: "From: Mail Delivery System <Mailer-Daemon@a.com>\r\n" +
+				"Subject: Mail delivery failed: returning message to sender\r\n" +
+				"X-Failed-Recipients: bob@b.com, carol@c.com\r\n" +
+				"\r\n" +
+				"  bob@b.com\r\n" +
+				"    mailbox is full: retry timeout exceeded\r\n",
+			want: []report.Bounce{
+				{Recipient: "bob@b.com", Action: report.ActionFailed, Status: "5.2.2"},
+				{Recipient: "carol@c.com", Action: report.ActionFailed, Status: "5.2.2"}}},
+		{desc: "delayed",
+			raw: "From: postmaster@a.com\r\n" +
+				"Subject: Warning: could not send message for past 4 hours\r\n" +
+				"\r\n" +
+				"bob@b.com: host mx.b.com said: 451 4.3.0 try again later\r\n",
+			want: []report.Bounce{{Recipient: "bob@b.com", Action: report.ActionDelayed,
+				Status: "4.3.0", Diagnostic: "bob@b.com: host mx.b.com said: 451 4.3.0 try again later"}}},
+	} {
+		e, err := mime.ReadEntity(strings.NewReader(c.raw))
+		assert.NoError(t, err, c.desc)
+		got, err := report.ParseBounce(e)
+		assert.NoError(t, err, c.desc)
+		assert.Equal(t, c.want, got, c.desc)
+	}
+
+	// not a bounce
+	e, err := mime.ReadEntity(strings.NewReader("From: alice@a.com\r\nSubject: hello\r\n\r\nbob@b.com"))
+	assert.NoError(t, err)
+	_, err = report.ParseBounce(e)
+	assert.Equal(t, report.ErrNoReport, err)
+}
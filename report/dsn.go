@@ -0,0 +1,213 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+)
+
+// Action indicates the action performed by the reporting MTA
+type Action string
+
+const (
+	ActionFailed    Action = "failed"    // could not be delivered
+	ActionDelayed   Action = "delayed"   // not yet delivered, will retry
+	ActionDelivered Action = "delivered" // successfully delivered
+	ActionRelayed   Action = "relayed"   // relayed to non-DSN aware environment
+	ActionExpanded  Action = "expanded"  // delivered and forwarded to multiple recipients
+)
+
+// status-code = DIGIT "." 1*3DIGIT "." 1*3DIGIT (RFC 3463)
+var statusCode = regexp.MustCompile(`^[245]\.\d{1,3}\.\d{1,3}$`)
+
+// DeliveryStatus represents the message/delivery-status body of a Delivery
+// Status Notification (RFC 3464), describing a single message and the status
+// of each of its recipients
+//
+// Fields which take a type, such as ReportingMTA ("dns; mx.example.com") and
+// FinalRecipient ("rfc822; alice@example.com") default to dns and rfc822
+// types respectively when output if value does not specify its own type
+//
+// Syntax:
+//
+//	delivery-status-content =  per-message-fields
+//	                           1*( CRLF per-recipient-fields )
+//	per-message-fields      =  [ original-envelope-id-field CRLF ]
+//	                           reporting-mta-field CRLF
+//	                           [ dsn-gateway-field CRLF ]
+//	                           [ received-from-mta-field CRLF ]
+//	                           [ arrival-date-field CRLF ]
+//	                           *( extension-field CRLF )
+//	per-recipient-fields    =  [ original-recipient-field CRLF ]
+//	                           final-recipient-field CRLF
+//	                           action-field CRLF
+//	                           status-field CRLF
+//	                           [ remote-mta-field CRLF ]
+//	                           [ diagnostic-code-field CRLF ]
+//	                           [ last-attempt-date-field CRLF ]
+//	                           [ final-log-id-field CRLF ]
+//	                           [ will-retry-until-field CRLF ]
+//	                           *( extension-field CRLF )
+type DeliveryStatus struct {
+	OriginalEnvelopeID string
+	ReportingMTA       string
+	DSNGateway         string
+	ReceivedFromMTA    string
+	ArrivalDate        time.Time
+	Recipients         []RecipientStatus
+}
+
+// RecipientStatus represents the per-recipient fields of a DeliveryStatus
+type RecipientStatus struct {
+	OriginalRecipient string
+	FinalRecipient    string
+	Action            Action
+	Status            string // RFC 3463 status code, eg 5.1.1
+	RemoteMTA         string
+	DiagnosticCode    string // eg "smtp; 550 5.1.1 user unknown"
+	LastAttemptDate   time.Time
+	FinalLogID        string
+	WillRetryUntil    time.Time
+}
+
+// Address returns FinalRecipient without its address type
+func (r RecipientStatus) Address() string {
+	return typedValue(r.FinalRecipient)
+}
+
+// Diagnostic returns DiagnosticCode without its diagnostic type
+func (r RecipientStatus) Diagnostic() string {
+	return typedValue(r.DiagnosticCode)
+}
+
+// Permanent reports whether status indicates a permanent failure
+func (r RecipientStatus) Permanent() bool {
+	return len(r.Status) > 0 && r.Status[0] == '5'
+}
+
+// Validate checks required fields are present and well formed
+func (d *DeliveryStatus) Validate() error {
+	if d.ReportingMTA == "" {
+		return errors.New("delivery-status: missing Reporting-MTA")
+	}
+	if len(d.Recipients) == 0 {
+		return errors.New("delivery-status: must contain at least 1 recipient")
+	}
+
+	for _, r := range d.Recipients {
+		if r.FinalRecipient == "" {
+			return errors.New("delivery-status: missing Final-Recipient")
+		}
+		switch r.Action {
+		case ActionFailed, ActionDelayed, ActionDelivered, ActionRelayed, ActionExpanded:
+		default:
+			return fmt.Errorf("delivery-status: invalid Action %q for %s", r.Action, r.Address())
+		}
+		if !statusCode.MatchString(r.Status) {
+			return fmt.Errorf("delivery-status: invalid Status %q for %s", r.Status, r.Address())
+		}
+	}
+
+	return nil
+}
+
+// Entity returns the message/delivery-status entity
+func (d *DeliveryStatus) Entity() *mime.Entity {
+	var msg fields
+	msg.add("Original-Envelope-Id", d.OriginalEnvelopeID)
+	msg.addTyped("Reporting-MTA", "dns", d.ReportingMTA)
+	msg.addTyped("DSN-Gateway", "dns", d.DSNGateway)
+	msg.addTyped("Received-From-MTA", "dns", d.ReceivedFromMTA)
+	msg.addDate("Arrival-Date", d.ArrivalDate)
+
+	blocks := []fields{msg}
+	for _, r := range d.Recipients {
+		var rcpt fields
+		rcpt.addTyped("Original-Recipient", "rfc822", r.OriginalRecipient)
+		rcpt.addTyped("Final-Recipient", "rfc822", r.FinalRecipient)
+		rcpt.add("Action", string(r.Action))
+		rcpt.add("Status", r.Status)
+		rcpt.addTyped("Remote-MTA", "dns", r.RemoteMTA)
+		rcpt.addTyped("Diagnostic-Code", "smtp", r.DiagnosticCode)
+		rcpt.addDate("Last-Attempt-Date", r.LastAttemptDate)
+		rcpt.add("Final-Log-ID", r.FinalLogID)
+		rcpt.addDate("Will-Retry-Until", r.WillRetryUntil)
+		blocks = append(blocks, rcpt)
+	}
+
+	return mime.NewEntity([]header.Header{
+		header.NewContentType("message/delivery-status", nil),
+	}, encodeBlocks(blocks...))
+}
+
+// NewDeliveryReport returns a multipart/report; report-type=delivery-status
+// entity consisting of human readable text, the delivery status and the
+// original message, or only its headers if headersOnly is set. Original
+// may be nil if it is not to be returned
+func NewDeliveryReport(headers []header.Header, text string, status *DeliveryStatus, original *mime.Entity, headersOnly bool) *mime.Entity {
+	return NewDeliveryReportFrom(nil, headers, text, status, original, headersOnly)
+}
+
+// NewDeliveryReportFrom is NewDeliveryReport, using src for the boundary
+func NewDeliveryReportFrom(src *header.Source, headers []header.Header, text string, status *DeliveryStatus, original *mime.Entity, headersOnly bool) *mime.Entity {
+	return newReport(src, "delivery-status", headers, text, status.Entity(), original, headersOnly)
+}
+
+// ParseDeliveryStatus parses the first message/delivery-status part of e,
+// returning ErrNoReport if there is none
+func ParseDeliveryStatus(e *mime.Entity) (*DeliveryStatus, error) {
+	part := findPart(e, "message/delivery-status", "message/global-delivery-status")
+	if part == nil {
+		return nil, ErrNoReport
+	}
+	content, err := part.Content()
+	if err != nil {
+		return nil, fmt.Errorf("delivery-status: %w", err)
+	}
+	blocks, err := parseBlocks(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("delivery-status: %w", err)
+	}
+	if len(blocks) == 0 {
+		return nil, errors.New("delivery-status: empty report")
+	}
+
+	msg := blocks[0]
+	d := &DeliveryStatus{
+		OriginalEnvelopeID: msg.get("Original-Envelope-Id"),
+		ReportingMTA:       msg.get("Reporting-MTA"),
+		DSNGateway:         msg.get("DSN-Gateway"),
+		ReceivedFromMTA:    msg.get("Received-From-MTA"),
+		ArrivalDate:        msg.getDate("Arrival-Date"),
+	}
+	for _, rcpt := range blocks[1:] {
+		d.Recipients = append(d.Recipients, RecipientStatus{
+			OriginalRecipient: rcpt.get("Original-Recipient"),
+			FinalRecipient:    rcpt.get("Final-Recipient"),
+			Action:            Action(strings.ToLower(rcpt.get("Action"))),
+			Status:            statusPrefix(rcpt.get("Status")),
+			RemoteMTA:         rcpt.get("Remote-MTA"),
+			DiagnosticCode:    rcpt.get("Diagnostic-Code"),
+			LastAttemptDate:   rcpt.getDate("Last-Attempt-Date"),
+			FinalLogID:        rcpt.get("Final-Log-ID"),
+			WillRetryUntil:    rcpt.getDate("Will-Retry-Until"),
+		})
+	}
+
+	return d, nil
+}
+
+// statusPrefix removes any comment following status code, eg "5.1.1 (user unknown)"
+func statusPrefix(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] == ' ' || s[i] == '\t' || s[i] == '(' {
+			return s[:i]
+		}
+	}
+	return s
+}
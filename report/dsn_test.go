@@ -0,0 +1,123 @@
+package report_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+	"github.com/jimtsao/go-email/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func originalMessage() *mime.Entity {
+	return mime.NewEntity([]header.Header{
+		header.Address{Field: header.AddressFrom, Value: "alice@a.com"},
+		header.Address{Field: header.AddressTo, Value: "bob@b.com"},
+		header.Subject("foo"),
+		header.MessageID("<1@a.com>"),
+	}, "hello world")
+}
+
+func TestDeliveryStatus(t *testing.T) {
+	arrival := time.Date(2000, time.January, 2, 12, 40, 20, 0, time.UTC)
+	ds := &report.DeliveryStatus{
+		ReportingMTA: "mx.b.com",
+		ArrivalDate:  arrival,
+		Recipients: []report.RecipientStatus{{
+			FinalRecipient: "bob@b.com",
+			Action:         report.ActionFailed,
+			Status:         "5.1.1",
+			DiagnosticCode: "550 5.1.1 user unknown",
+		}, {
+			OriginalRecipient: "rfc822; carol@b.com",
+			FinalRecipient:    "rfc822; carol@c.com",
+			Action:            report.ActionDelayed,
+			Status:            "4.2.2",
+			WillRetryUntil:    arrival.Add(72 * time.Hour),
+		}},
+	}
+	assert.NoError(t, ds.Validate())
+
+	want := "Content-Type: message/delivery-status\r\n" +
+		"\r\n" +
+		"Reporting-MTA: dns; mx.b.com\r\n" +
+		"Arrival-Date: Sun, 2 Jan 2000 12:40:20 +0000\r\n" +
+		"\r\n" +
+		"Final-Recipient: rfc822; bob@b.com\r\n" +
+		"Action: failed\r\n" +
+		"Status: 5.1.1\r\n" +
+		"Diagnostic-Code: smtp; 550 5.1.1 user unknown\r\n" +
+		"\r\n" +
+		"Original-Recipient: rfc822; carol@b.com\r\n" +
+		"Final-Recipient: rfc822; carol@c.com\r\n" +
+		"Action: delayed\r\n" +
+		"Status: 4.2.2\r\n" +
+		"Will-Retry-Until: Wed, 5 Jan 2000 12:40:20 +0000\r\n"
+	assert.Equal(t, want, ds.Entity().String())
+
+	// round trip
+	dsn := report.NewDeliveryReport([]header.Header{
+		header.Address{Field: header.AddressFrom, Value: "mailer-daemon@b.com"},
+		header.Subject("Delivery Status Notification (Failure)"),
+	}, "could not deliver", ds, originalMessage(), false)
+	parsed, err := mime.ReadEntity(strings.NewReader(dsn.String()))
+	assert.NoError(t, err)
+	mediatype, params := parsed.ContentType()
+	assert.Equal(t, "multipart/report", mediatype)
+	assert.Equal(t, "delivery-status", params["report-type"])
+
+	got, err := report.ParseDeliveryStatus(parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, "dns; mx.b.com", got.ReportingMTA)
+	assert.True(t, arrival.Equal(got.ArrivalDate))
+	assert.Len(t, got.Recipients, 2)
+	assert.Equal(t, "bob@b.com", got.Recipients[0].Address())
+	assert.Equal(t, "550 5.1.1 user unknown", got.Recipients[0].Diagnostic())
+	assert.True(t, got.Recipients[0].Permanent())
+	assert.Equal(t, report.ActionDelayed, got.Recipients[1].Action)
+	assert.False(t, got.Recipients[1].Permanent())
+	assert.Equal(t, "foo", parsed.Parts()[2].Embedded().Get("Subject"))
+
+	// headers only
+	dsn = report.NewDeliveryReport(nil, "could not deliver", ds, originalMessage(), true)
+	assert.Contains(t, dsn.String(), "Content-Type: text/rfc822-headers\r\n"+
+		"\r\n"+
+		"From: <alice@a.com>\r\n")
+	assert.NotContains(t, dsn.String(), "hello world")
+
+	// non-ascii text
+	dsn = report.NewDeliveryReport(nil, "échec de livraison", ds, nil, false)
+	parsed, err = mime.ReadEntity(strings.NewReader(dsn.String()))
+	assert.NoError(t, err)
+	text := parsed.Parts()[0]
+	assert.Equal(t, "base64", text.Get("Content-Transfer-Encoding"))
+	content, err := text.Content()
+	assert.NoError(t, err)
+	assert.Equal(t, "échec de livraison", string(content))
+}
+
+func TestDeliveryStatusValidate(t *testing.T) {
+	rcpt := report.RecipientStatus{FinalRecipient: "bob@b.com", Action: report.ActionFailed, Status: "5.1.1"}
+	for _, c := range []struct {
+		desc string
+		ds   report.DeliveryStatus
+	}{
+		{"missing reporting-mta", report.DeliveryStatus{Recipients: []report.RecipientStatus{rcpt}}},
+		{"missing recipients", report.DeliveryStatus{ReportingMTA: "mx.b.com"}},
+		{"missing final-recipient", report.DeliveryStatus{ReportingMTA: "mx.b.com",
+			Recipients: []report.RecipientStatus{{Action: report.ActionFailed, Status: "5.1.1"}}}},
+		{"invalid action", report.DeliveryStatus{ReportingMTA: "mx.b.com",
+			Recipients: []report.RecipientStatus{{FinalRecipient: "bob@b.com", Action: "bounced", Status: "5.1.1"}}}},
+		{"invalid status", report.DeliveryStatus{ReportingMTA: "mx.b.com",
+			Recipients: []report.RecipientStatus{{FinalRecipient: "bob@b.com", Action: report.ActionFailed, Status: "550"}}}},
+	} {
+		assert.Error(t, c.ds.Validate(), c.desc)
+	}
+}
+
+func TestParseDeliveryStatusNoReport(t *testing.T) {
+	_, err := report.ParseDeliveryStatus(originalMessage())
+	assert.Equal(t, report.ErrNoReport, err)
+}
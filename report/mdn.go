@@ -0,0 +1,282 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jimtsao/go-email/charset"
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+)
+
+// Disposition action and sending modes
+const (
+	ManualAction          = "manual-action"
+	AutomaticAction       = "automatic-action"
+	SentManually          = "MDN-sent-manually"
+	SentAutomatically     = "MDN-sent-automatically"
+	DispositionDisplayed  = "displayed"
+	DispositionDeleted    = "deleted"
+	DispositionDispatched = "dispatched"
+	DispositionProcessed  = "processed"
+)
+
+// Disposition describes what happened to a message once received
+//
+// usage:
+//
+//	d := Disposition{ActionMode: ManualAction, SendingMode: SentManually, Type: DispositionDisplayed}
+//
+// Syntax:
+//
+//	disposition-field    = "Disposition" ":" OWS disposition-mode OWS ";"
+//	                       OWS disposition-type
+//	                       [ OWS "/" OWS disposition-modifier
+//	                       *( OWS "," OWS disposition-modifier ) ] OWS
+//	disposition-mode     = action-mode OWS "/" OWS sending-mode
+//	action-mode          = "manual-action" / "automatic-action"
+//	sending-mode         = "MDN-sent-manually" / "MDN-sent-automatically"
+//	disposition-type     = "displayed" / "deleted" / "dispatched" / "processed"
+//	disposition-modifier = "error" / disposition-modifier-extension
+type Disposition struct {
+	ActionMode  string
+	SendingMode string
+	Type        string
+	Modifiers   []string
+}
+
+func (d Disposition) String() string {
+	s := fmt.Sprintf("%s/%s; %s", d.ActionMode, d.SendingMode, d.Type)
+	if len(d.Modifiers) > 0 {
+		s += "/" + strings.Join(d.Modifiers, ",")
+	}
+	return s
+}
+
+// Validate checks disposition mode and type are recognised
+func (d Disposition) Validate() error {
+	if !strings.EqualFold(d.ActionMode, ManualAction) && !strings.EqualFold(d.ActionMode, AutomaticAction) {
+		return fmt.Errorf("disposition: invalid action mode %q", d.ActionMode)
+	}
+	if !strings.EqualFold(d.SendingMode, SentManually) && !strings.EqualFold(d.SendingMode, SentAutomatically) {
+		return fmt.Errorf("disposition: invalid sending mode %q", d.SendingMode)
+	}
+	switch strings.ToLower(d.Type) {
+	case DispositionDisplayed, DispositionDeleted, DispositionDispatched, DispositionProcessed:
+	default:
+		return fmt.Errorf("disposition: invalid disposition type %q", d.Type)
+	}
+	return nil
+}
+
+// ParseDisposition parses the body of a Disposition field
+func ParseDisposition(s string) (Disposition, error) {
+	mode, rest, found := strings.Cut(s, ";")
+	if !found {
+		return Disposition{}, fmt.Errorf("disposition: missing disposition type (%q)", s)
+	}
+	action, sending, found := strings.Cut(mode, "/")
+	if !found {
+		return Disposition{}, fmt.Errorf("disposition: missing sending mode (%q)", s)
+	}
+
+	d := Disposition{
+		ActionMode:  strings.TrimSpace(action),
+		SendingMode: strings.TrimSpace(sending),
+	}
+	typ, modifiers, found := strings.Cut(rest, "/")
+	d.Type = strings.ToLower(strings.TrimSpace(typ))
+	if found {
+		for _, m := range strings.Split(modifiers, ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				d.Modifiers = append(d.Modifiers, m)
+			}
+		}
+	}
+
+	return d, d.Validate()
+}
+
+// DispositionNotification represents the message/disposition-notification
+// body of a Message Disposition Notification (RFC 8098)
+//
+// Fields which take a type, such as FinalRecipient ("rfc822; bob@example.com")
+// default to rfc822 when output if value does not specify its own type
+//
+// Syntax:
+//
+//	disposition-notification-content =
+//	                     [ reporting-ua-field CRLF ]
+//	                     [ mdn-gateway-field CRLF ]
+//	                     [ original-recipient-field CRLF ]
+//	                     final-recipient-field CRLF
+//	                     [ original-message-id-field CRLF ]
+//	                     disposition-field CRLF
+//	                     *( error-field CRLF )
+//	                     *( extension-field CRLF )
+type DispositionNotification struct {
+	ReportingUA       string // eg "mail.example.com; Example Mail 1.0"
+	MDNGateway        string
+	OriginalRecipient string
+	FinalRecipient    string
+	OriginalMessageID string
+	Disposition       Disposition
+	Errors            []string
+}
+
+// Address returns FinalRecipient without its address type
+func (n *DispositionNotification) Address() string {
+	return typedValue(n.FinalRecipient)
+}
+
+// Validate checks required fields are present and well formed
+func (n *DispositionNotification) Validate() error {
+	if n.FinalRecipient == "" {
+		return errors.New("disposition-notification: missing Final-Recipient")
+	}
+	if n.OriginalMessageID != "" {
+		if err := header.MessageID(n.OriginalMessageID).Validate(); err != nil {
+			return fmt.Errorf("disposition-notification: Original-Message-ID: %w", err)
+		}
+	}
+	if err := n.Disposition.Validate(); err != nil {
+		return fmt.Errorf("disposition-notification: %w", err)
+	}
+	return nil
+}
+
+// Entity returns the message/disposition-notification entity
+func (n *DispositionNotification) Entity() *mime.Entity {
+	var f fields
+	f.add("Reporting-UA", n.ReportingUA)
+	f.addTyped("MDN-Gateway", "dns", n.MDNGateway)
+	f.addTyped("Original-Recipient", "rfc822", n.OriginalRecipient)
+	f.addTyped("Final-Recipient", "rfc822", n.FinalRecipient)
+	f.add("Original-Message-ID", n.OriginalMessageID)
+	f.add("Disposition", n.Disposition.String())
+	for _, e := range n.Errors {
+		f.add("Error", e)
+	}
+
+	return mime.NewEntity([]header.Header{
+		header.NewContentType("message/disposition-notification", nil),
+	}, encodeBlocks(f))
+}
+
+// NewDispositionReport returns a multipart/report; report-type=disposition-notification
+// entity consisting of human readable text, the disposition notification and the
+// original message, or only its headers if headersOnly is set. Original may be nil
+// if it is not to be returned
+func NewDispositionReport(headers []header.Header, text string, mdn *DispositionNotification, original *mime.Entity, headersOnly bool) *mime.Entity {
+	return NewDispositionReportFrom(nil, headers, text, mdn, original, headersOnly)
+}
+
+// NewDispositionReportFrom is NewDispositionReport, using src for the boundary
+func NewDispositionReportFrom(src *header.Source, headers []header.Header, text string, mdn *DispositionNotification, original *mime.Entity, headersOnly bool) *mime.Entity {
+	return newReport(src, "disposition-notification", headers, text, mdn.Entity(), original, headersOnly)
+}
+
+// NewReadReceipt returns a displayed notification for a parsed message which
+// requested one via Disposition-Notification-To, see mime.ReadEntity. From is
+// the address of the recipient sending the receipt. The original headers are
+// returned rather than the full message, as recommended by RFC 8098
+func NewReadReceipt(original *mime.Entity, from string) (*mime.Entity, error) {
+	return NewReadReceiptFrom(nil, original, from)
+}
+
+// NewReadReceiptFrom is NewReadReceipt, using src for the Date, Message-ID
+// and boundary of the receipt
+func NewReadReceiptFrom(src *header.Source, original *mime.Entity, from string) (*mime.Entity, error) {
+	to := original.Get("Disposition-Notification-To")
+	if to == "" {
+		return nil, errors.New("disposition-notification: original does not request a notification")
+	}
+	addrs, err := header.ParseAddressList(from)
+	if err != nil {
+		return nil, fmt.Errorf("disposition-notification: from: %w", err)
+	} else if len(addrs) != 1 {
+		return nil, errors.New("disposition-notification: from must be a single mailbox")
+	}
+	addr := addrs[0].Address
+
+	var id string
+	if ids := header.ParseMsgIDs(original.Get("Message-ID")); len(ids) > 0 {
+		id = ids[0]
+	}
+	mdn := &DispositionNotification{
+		FinalRecipient:    addr,
+		OriginalMessageID: id,
+		Disposition: Disposition{
+			ActionMode:  ManualAction,
+			SendingMode: SentManually,
+			Type:        DispositionDisplayed,
+		},
+	}
+	if err := mdn.Validate(); err != nil {
+		return nil, err
+	}
+
+	subject := original.Get("Subject")
+	if dec, err := charset.DecodeHeader(subject); err == nil {
+		subject = dec
+	}
+	domain := addr[strings.LastIndex(addr, "@")+1:]
+	headers := []header.Header{
+		header.MIMEVersion{},
+		header.Date(src.Now()),
+		header.Address{Field: header.AddressFrom, Value: from},
+		header.Address{Field: header.AddressTo, Value: to},
+		header.Subject("Read: " + subject),
+		src.MessageID(domain),
+	}
+	if id != "" {
+		headers = append(headers, header.InReplyTo{id})
+	}
+	text := fmt.Sprintf("This is a receipt for the message you sent to %s\r\n\r\n"+
+		"Subject: %s\r\n\r\n"+
+		"Note: This receipt only acknowledges that the message was displayed "+
+		"on the recipient's computer. There is no guarantee that the recipient "+
+		"has read or understood the message contents.\r\n", addr, subject)
+
+	return NewDispositionReportFrom(src, headers, text, mdn, original, true), nil
+}
+
+// ParseDispositionNotification parses the first message/disposition-notification
+// part of e, returning ErrNoReport if there is none
+func ParseDispositionNotification(e *mime.Entity) (*DispositionNotification, error) {
+	part := findPart(e, "message/disposition-notification", "message/global-disposition-notification")
+	if part == nil {
+		return nil, ErrNoReport
+	}
+	content, err := part.Content()
+	if err != nil {
+		return nil, fmt.Errorf("disposition-notification: %w", err)
+	}
+	blocks, err := parseBlocks(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("disposition-notification: %w", err)
+	}
+	if len(blocks) == 0 {
+		return nil, errors.New("disposition-notification: empty report")
+	}
+
+	f := blocks[0]
+	n := &DispositionNotification{
+		ReportingUA:       f.get("Reporting-UA"),
+		MDNGateway:        f.get("MDN-Gateway"),
+		OriginalRecipient: f.get("Original-Recipient"),
+		FinalRecipient:    f.get("Final-Recipient"),
+		OriginalMessageID: f.get("Original-Message-ID"),
+	}
+	for _, h := range f {
+		if strings.EqualFold(h.Name(), "Error") {
+			n.Errors = append(n.Errors, header.Value(h))
+		}
+	}
+	if n.Disposition, err = ParseDisposition(f.get("Disposition")); err != nil {
+		return n, fmt.Errorf("disposition-notification: %w", err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,124 @@
+package report_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+	"github.com/jimtsao/go-email/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisposition(t *testing.T) {
+	d := report.Disposition{
+		ActionMode:  report.AutomaticAction,
+		SendingMode: report.SentAutomatically,
+		Type:        report.DispositionDeleted,
+		Modifiers:   []string{"error"},
+	}
+	assert.NoError(t, d.Validate())
+	assert.Equal(t, "automatic-action/MDN-sent-automatically; deleted/error", d.String())
+
+	got, err := report.ParseDisposition(" manual-action / MDN-sent-manually ; Displayed ")
+	assert.NoError(t, err)
+	assert.Equal(t, report.Disposition{
+		ActionMode:  report.ManualAction,
+		SendingMode: report.SentManually,
+		Type:        report.DispositionDisplayed,
+	}, got)
+
+	for _, s := range []string{
+		"manual-action; displayed",
+		"manual-action/MDN-sent-manually",
+		"manual-action/MDN-sent-manually; read",
+		"foo/MDN-sent-manually; displayed",
+	} {
+		_, err := report.ParseDisposition(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestDispositionNotification(t *testing.T) {
+	mdn := &report.DispositionNotification{
+		ReportingUA:       "b.com; Example Mail 1.0",
+		FinalRecipient:    "bob@b.com",
+		OriginalMessageID: "<1@a.com>",
+		Disposition: report.Disposition{
+			ActionMode:  report.ManualAction,
+			SendingMode: report.SentManually,
+			Type:        report.DispositionDisplayed,
+		},
+	}
+	assert.NoError(t, mdn.Validate())
+
+	want := "Content-Type: message/disposition-notification\r\n" +
+		"\r\n" +
+		"Reporting-UA: b.com; Example Mail 1.0\r\n" +
+		"Final-Recipient: rfc822; bob@b.com\r\n" +
+		"Original-Message-ID: <1@a.com>\r\n" +
+		"Disposition: manual-action/MDN-sent-manually; displayed\r\n"
+	assert.Equal(t, want, mdn.Entity().String())
+
+	// round trip
+	msg := report.NewDispositionReport([]header.Header{
+		header.Address{Field: header.AddressFrom, Value: "bob@b.com"},
+	}, "displayed", mdn, originalMessage(), true)
+	parsed, err := mime.ReadEntity(strings.NewReader(msg.String()))
+	assert.NoError(t, err)
+	_, params := parsed.ContentType()
+	assert.Equal(t, "disposition-notification", params["report-type"])
+	got, err := report.ParseDispositionNotification(parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, "rfc822; bob@b.com", got.FinalRecipient)
+	assert.Equal(t, "bob@b.com", got.Address())
+	assert.Equal(t, "<1@a.com>", got.OriginalMessageID)
+	assert.Equal(t, mdn.Disposition, got.Disposition)
+
+	// invalid
+	for _, n := range []*report.DispositionNotification{
+		{Disposition: mdn.Disposition},
+		{FinalRecipient: "bob@b.com"},
+		{FinalRecipient: "bob@b.com", OriginalMessageID: "1@a.com", Disposition: mdn.Disposition},
+	} {
+		assert.Error(t, n.Validate())
+	}
+
+	// not a report
+	_, err = report.ParseDispositionNotification(originalMessage())
+	assert.ErrorIs(t, err, report.ErrNoReport)
+}
+
+func TestReadReceipt(t *testing.T) {
+	original := originalMessage()
+	_, err := report.NewReadReceipt(original, "bob@b.com")
+	assert.Error(t, err)
+
+	original.Headers = append(original.Headers, header.Address{
+		Field: header.AddressDispositionNotificationTo, Value: "Alice <alice@a.com>"})
+	assert.Equal(t, "Disposition-Notification-To: \"Alice\" <alice@a.com>\r\n",
+		original.Headers[len(original.Headers)-1].String())
+
+	_, err = report.NewReadReceipt(original, "bob@")
+	assert.Error(t, err)
+
+	src := header.NewSeededSource(1, time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC))
+	receipt, err := report.NewReadReceiptFrom(src, original, "Bob <bob@b.com>")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0", receipt.Get("MIME-Version"))
+	assert.Equal(t, "Sat, 1 Jan 2000 00:00:00 +0000", receipt.Get("Date"))
+	assert.Regexp(t, `^<[0-9a-z]+\.[0-9a-v]+@b\.com>$`, receipt.Get("Message-ID"))
+	assert.Equal(t, "\"Alice\" <alice@a.com>", receipt.Get("To"))
+	again, _ := report.NewReadReceiptFrom(header.NewSeededSource(1, src.Now()), original, "Bob <bob@b.com>")
+	assert.Equal(t, receipt.String(), again.String(), "reproducible")
+	assert.Equal(t, "Read: foo", receipt.Get("Subject"))
+	assert.Equal(t, "<1@a.com>", receipt.Get("In-Reply-To"))
+
+	got, err := report.ParseDispositionNotification(receipt)
+	assert.NoError(t, err)
+	assert.Equal(t, "rfc822; bob@b.com", got.FinalRecipient)
+	assert.Equal(t, "bob@b.com", got.Address())
+	assert.Equal(t, report.DispositionDisplayed, got.Disposition.Type)
+	assert.Equal(t, "text/rfc822-headers", receipt.Parts()[2].Get("Content-Type"))
+}
@@ -0,0 +1,227 @@
+// package report builds and parses multipart/report messages (RFC 6522),
+// consisting of a human readable part, a machine parsable report and
+// optionally the original message or its headers
+package report
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jimtsao/go-email/base64"
+	"github.com/jimtsao/go-email/charset"
+	"github.com/jimtsao/go-email/header"
+	"github.com/jimtsao/go-email/mime"
+	"github.com/jimtsao/go-email/syntax"
+)
+
+// ErrNoReport is returned when an entity does not contain the requested report
+var ErrNoReport = errors.New("report: no report found")
+
+// fields is an ordered group of report fields, which share header field syntax
+type fields []header.Header
+
+// add appends field, ignoring empty values
+func (f *fields) add(name string, value string) {
+	if value != "" {
+		*f = append(*f, header.Field{FieldName: name, Value: value})
+	}
+}
+
+func (f *fields) addDate(name string, t time.Time) {
+	if !t.IsZero() {
+		f.add(name, t.Format(header.TimeRFC5322))
+	}
+}
+
+// typed adds value in 'type ";" value' form, using
+// defaultType if value does not specify its own type
+func (f *fields) addTyped(name string, defaultType string, value string) {
+	if value != "" && !strings.Contains(value, ";") {
+		value = defaultType + "; " + value
+	}
+	f.add(name, value)
+}
+
+func (f fields) get(name string) string {
+	for _, h := range f {
+		if strings.EqualFold(h.Name(), name) {
+			return header.Value(h)
+		}
+	}
+	return ""
+}
+
+func (f fields) getDate(name string) time.Time {
+	t, _ := header.ParseDate(f.get(name))
+	return t
+}
+
+func (f fields) String() string {
+	sb := strings.Builder{}
+	for _, h := range f {
+		sb.WriteString(h.String())
+	}
+	return sb.String()
+}
+
+// encodeBlocks joins groups of fields with a blank line
+func encodeBlocks(blocks ...fields) string {
+	s := make([]string, len(blocks))
+	for i, b := range blocks {
+		s[i] = b.String()
+	}
+	return strings.Join(s, "\r\n")
+}
+
+// parseBlocks splits a report body into groups of fields separated by blank lines
+func parseBlocks(body string) ([]fields, error) {
+	var blocks []fields
+	for strings.TrimSpace(body) != "" {
+		body = strings.TrimLeft(body, "\r\n")
+		e, err := mime.ReadEntity(strings.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("report: %w", err)
+		}
+		blocks = append(blocks, fields(e.Headers))
+		body = e.Body.String()
+	}
+	return blocks, nil
+}
+
+// typedValue returns value with any 'type ";"' prefix removed
+func typedValue(s string) string {
+	if _, v, found := strings.Cut(s, ";"); found {
+		return strings.TrimSpace(v)
+	}
+	return s
+}
+
+// findPart returns first descendant of e with one of the given media types
+func findPart(e *mime.Entity, mediatypes ...string) *mime.Entity {
+	var found *mime.Entity
+	e.Walk(func(part *mime.Entity) error {
+		mediatype, _ := part.ContentType()
+		for _, m := range mediatypes {
+			if mediatype == m {
+				found = part
+				return mime.SkipAll
+			}
+		}
+		return nil
+	})
+	return found
+}
+
+// newReport assembles human readable text, machine parsable report
+// and the original message, or only its headers if headersOnly is set.
+// The boundary is read from src, crypto/rand is used if nil
+func newReport(src *header.Source, reportType string, headers []header.Header, text string, report *mime.Entity, original *mime.Entity, headersOnly bool) *mime.Entity {
+	parts := []*mime.Entity{textEntity(text), report}
+
+	if original != nil {
+		if headersOnly {
+			hh := fields(original.Headers)
+			parts = append(parts, mime.NewEntity([]header.Header{
+				header.NewContentType("text/rfc822-headers", nil),
+			}, hh.String()))
+		} else {
+			parts = append(parts, mime.NewMessageRFC822(original))
+		}
+	}
+
+	var r io.Reader
+	if src != nil {
+		r = src
+	}
+	return mime.NewMultipartReportFrom(r, reportType, headers, parts)
+}
+
+// textEntity returns human readable text entity,
+// base64 encoded if it contains non-ascii characters
+func textEntity(text string) *mime.Entity {
+	cte, body := "7bit", text
+	if !syntax.IsASCII(text) {
+		cte, body = "base64", base64.EncodeToString([]byte(text))
+	}
+	return mime.NewEntity([]header.Header{
+		header.NewContentType("text/plain", header.NewMIMEParams("charset", "utf-8")),
+		header.NewContentTransferEncoding(cte),
+	}, body)
+}
+
+// OriginalMessage holds the headers of the reported message, included
+// in a report as message/rfc822 or text/rfc822-headers. Addresses
+// are addr-specs and Subject is decoded to UTF-8
+type OriginalMessage struct {
+	From      string
+	To        []string
+	Cc        []string
+	Subject   string
+	Date      time.Time
+	MessageID string
+	Headers   []header.Header // all header fields, in order
+}
+
+// ParseOriginalMessage parses the headers of the reported message
+// included in e, returning ErrNoReport if there are none
+func ParseOriginalMessage(e *mime.Entity) (*OriginalMessage, error) {
+	part := findPart(e, "message/rfc822", "message/global", "text/rfc822-headers")
+	if part == nil {
+		return nil, ErrNoReport
+	}
+
+	var f fields
+	if inner := part.Embedded(); inner != nil {
+		f = inner.Headers
+	} else {
+		content, err := part.Content()
+		if err != nil {
+			return nil, fmt.Errorf("report: %w", err)
+		}
+		blocks, err := parseBlocks(string(content))
+		if err != nil {
+			return nil, err
+		}
+		if len(blocks) > 0 {
+			f = blocks[0]
+		}
+	}
+	if len(f) == 0 {
+		return nil, ErrNoReport
+	}
+
+	m := &OriginalMessage{
+		Subject: f.get("Subject"),
+		Date:    f.getDate("Date"),
+		To:      addrSpecs(f.get("To")),
+		Cc:      addrSpecs(f.get("Cc")),
+		Headers: f,
+	}
+	if from := addrSpecs(f.get("From")); len(from) > 0 {
+		m.From = from[0]
+	}
+	if ids := header.ParseMsgIDs(f.get("Message-ID")); len(ids) > 0 {
+		m.MessageID = ids[0]
+	}
+	if dec, err := charset.DecodeHeader(m.Subject); err == nil {
+		m.Subject = dec
+	}
+	return m, nil
+}
+
+// addrSpecs returns the addr-specs of an address list, ignoring
+// display names. Unparsable lists return nil
+func addrSpecs(s string) []string {
+	addrs, err := header.ParseAddressList(s)
+	if err != nil {
+		return nil
+	}
+	specs := make([]string, len(addrs))
+	for i, a := range addrs {
+		specs[i] = a.Address
+	}
+	return specs
+}
@@ -0,0 +1,402 @@
+package syntax
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kind is the lexical class of a Token
+type Kind int
+
+const (
+	EOF           Kind = iota
+	FWS                // folding white space
+	Comment            // "(" *([FWS] ccontent) [FWS] ")", may be nested
+	Atom               // 1*atext
+	DotAtom            // 1*atext 1*("." 1*atext)
+	QuotedString       // DQUOTE *([FWS] qcontent) [FWS] DQUOTE
+	DomainLiteral      // "[" *([FWS] dtext) [FWS] "]"
+	EncodedWord        // "=?" charset "?" encoding "?" encoded-text "?="
+	Special            // single character of specials, excluding those above
+)
+
+var kindNames = []string{"EOF", "FWS", "comment", "atom", "dot-atom",
+	"quoted-string", "domain-literal", "encoded-word", "special"}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Token is a lexical element of a structured header field body
+type Token struct {
+	Kind  Kind
+	Value string // raw text of token, as it appears in input
+	Pos   int    // byte offset of token in input
+}
+
+// Text returns the semantic content of the token. Quoted strings and
+// comments have their delimiters removed, quoted-pairs unescaped and
+// folding removed. Folding white space is returned as a single space.
+// Other tokens are returned as is
+//
+// eg, `"Joe \"Q\" Public"` returns `Joe "Q" Public`
+func (t Token) Text() string {
+	switch t.Kind {
+	case FWS:
+		return " "
+	case QuotedString, Comment:
+		return unquote(t.Value[1 : len(t.Value)-1])
+	case DomainLiteral:
+		return unfold(t.Value)
+	}
+	return t.Value
+}
+
+// SyntaxError describes where and why tokenizing failed
+type SyntaxError struct {
+	Pos int    // byte offset of error in input
+	Msg string // description of error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at offset %d: %s", e.Pos, e.Msg)
+}
+
+// Lexer splits a structured header field body (RFC 5322 section 3.2)
+// into tokens. Non us-ascii UTF-8 is accepted as atext, qtext, ctext
+// and dtext as per RFC 6532
+//
+// usage:
+//
+//	l := syntax.NewLexer(`"Joe Q. Public" <john.q.public@example.com>`)
+//	for {
+//		tok, err := l.Next()
+//		if err != nil || tok.Kind == syntax.EOF {
+//			break
+//		}
+//	}
+type Lexer struct {
+	s    string
+	pos  int
+	peek *Token
+}
+
+// NewLexer returns Lexer of field body s, which may be folded
+func NewLexer(s string) *Lexer {
+	return &Lexer{s: s}
+}
+
+// Tokenize returns all tokens of s, excluding EOF
+func Tokenize(s string) ([]Token, error) {
+	l := NewLexer(s)
+	var tokens []Token
+	for {
+		tok, err := l.Next()
+		if err != nil {
+			return tokens, err
+		} else if tok.Kind == EOF {
+			return tokens, nil
+		}
+		tokens = append(tokens, tok)
+	}
+}
+
+// Peek returns the next token without consuming it
+func (l *Lexer) Peek() (Token, error) {
+	if l.peek != nil {
+		return *l.peek, nil
+	}
+	tok, err := l.Next()
+	if err != nil {
+		return tok, err
+	}
+	l.peek = &tok
+	return tok, nil
+}
+
+// Next returns the next token, or a token of kind EOF at end of input
+func (l *Lexer) Next() (Token, error) {
+	if l.peek != nil {
+		tok := *l.peek
+		l.peek = nil
+		return tok, nil
+	}
+
+	start := l.pos
+	if start >= len(l.s) {
+		return Token{Kind: EOF, Pos: start}, nil
+	}
+
+	var kind Kind
+	var err error
+	switch c := l.s[start]; {
+	case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		kind, err = FWS, l.fws()
+	case c == '(':
+		kind, err = Comment, l.comment()
+	case c == '"':
+		kind, err = QuotedString, l.quoted()
+	case c == '[':
+		kind, err = DomainLiteral, l.literal()
+	case c == '=' && encodedWord.MatchString(l.s[start:]) && l.encodedWord():
+		kind = EncodedWord
+	case isSpecials(rune(c)):
+		kind = Special
+		l.pos++
+	default:
+		kind, err = l.atom()
+	}
+
+	if err != nil {
+		return Token{}, err
+	}
+	return Token{Kind: kind, Value: l.s[start:l.pos], Pos: start}, nil
+}
+
+func (l *Lexer) errorf(pos int, format string, a ...interface{}) error {
+	return &SyntaxError{Pos: pos, Msg: fmt.Sprintf(format, a...)}
+}
+
+// fws consumes:
+//
+//	FWS             =   ([*WSP CRLF] 1*WSP)
+func (l *Lexer) fws() error {
+	start := l.pos
+	for l.pos < len(l.s) {
+		switch l.s[l.pos] {
+		case ' ', '\t':
+			l.pos++
+		case '\r', '\n':
+			// line break must be CRLF followed by WSP
+			if !strings.HasPrefix(l.s[l.pos:], "\r\n") {
+				return l.errorf(l.pos, "bare CR or LF")
+			}
+			if l.pos+2 >= len(l.s) || (l.s[l.pos+2] != ' ' && l.s[l.pos+2] != '\t') {
+				return l.errorf(l.pos, "CRLF not followed by white space")
+			}
+			l.pos += 3
+		default:
+			return nil
+		}
+	}
+	if l.pos == start {
+		return l.errorf(start, "expected white space")
+	}
+	return nil
+}
+
+// comment consumes:
+//
+//	comment         =   "(" *([FWS] ccontent) [FWS] ")"
+//	ccontent        =   ctext / quoted-pair / comment
+//	ctext           =   %d33-39 / %d42-91 / %d93-126
+func (l *Lexer) comment() error {
+	start := l.pos
+	depth := 0
+	for l.pos < len(l.s) {
+		switch l.s[l.pos] {
+		case '(':
+			depth++
+			l.pos++
+		case ')':
+			depth--
+			l.pos++
+			if depth == 0 {
+				return nil
+			}
+		case '\\':
+			if err := l.quotedPair(); err != nil {
+				return err
+			}
+		case ' ', '\t', '\r', '\n':
+			if err := l.fws(); err != nil {
+				return err
+			}
+		default:
+			if err := l.text(isVchar); err != nil {
+				return err
+			}
+		}
+	}
+	return l.errorf(start, "unterminated comment")
+}
+
+// quoted consumes:
+//
+//	quoted-string   =   [CFWS] DQUOTE *([FWS] qcontent) [FWS] DQUOTE [CFWS]
+//	qcontent        =   qtext / quoted-pair
+//	qtext           =   %d33 / %d35-91 / %d93-126
+func (l *Lexer) quoted() error {
+	start := l.pos
+	l.pos++
+	for l.pos < len(l.s) {
+		switch l.s[l.pos] {
+		case '"':
+			l.pos++
+			return nil
+		case '\\':
+			if err := l.quotedPair(); err != nil {
+				return err
+			}
+		case ' ', '\t', '\r', '\n':
+			if err := l.fws(); err != nil {
+				return err
+			}
+		default:
+			if err := l.text(isVchar); err != nil {
+				return err
+			}
+		}
+	}
+	return l.errorf(start, "unterminated quoted-string")
+}
+
+// literal consumes:
+//
+//	domain-literal  =   [CFWS] "[" *([FWS] dtext) [FWS] "]" [CFWS]
+//	dtext           =   %d33-90 / %d94-126
+func (l *Lexer) literal() error {
+	start := l.pos
+	l.pos++
+	for l.pos < len(l.s) {
+		switch l.s[l.pos] {
+		case ']':
+			l.pos++
+			return nil
+		case '[', '\\':
+			return l.errorf(l.pos, "invalid character %q in domain-literal", l.s[l.pos])
+		case ' ', '\t', '\r', '\n':
+			if err := l.fws(); err != nil {
+				return err
+			}
+		default:
+			if err := l.text(isDtext); err != nil {
+				return err
+			}
+		}
+	}
+	return l.errorf(start, "unterminated domain-literal")
+}
+
+// quotedPair consumes:
+//
+//	quoted-pair     =   "\" (VCHAR / WSP)
+func (l *Lexer) quotedPair() error {
+	if l.pos+1 >= len(l.s) {
+		return l.errorf(l.pos, "incomplete quoted-pair")
+	}
+	if c := l.s[l.pos+1]; c == ' ' || c == '\t' {
+		l.pos += 2
+		return nil
+	}
+	l.pos++
+	if err := l.text(isVchar); err != nil {
+		return l.errorf(l.pos-1, "invalid quoted-pair")
+	}
+	return nil
+}
+
+// text consumes a single character satisfying valid, or non us-ascii UTF-8
+func (l *Lexer) text(valid func(r rune) bool) error {
+	r, size := utf8.DecodeRuneInString(l.s[l.pos:])
+	if r == utf8.RuneError || !(valid(r) || r > 127) {
+		return l.errorf(l.pos, "invalid character %q", r)
+	}
+	l.pos += size
+	return nil
+}
+
+// atom consumes atom or dot-atom-text:
+//
+//	atom            =   [CFWS] 1*atext [CFWS]
+//	dot-atom-text   =   1*atext *("." 1*atext)
+func (l *Lexer) atom() (Kind, error) {
+	start := l.pos
+	if l.atext() == start {
+		r, _ := utf8.DecodeRuneInString(l.s[start:])
+		return EOF, l.errorf(start, "invalid character %q", r)
+	}
+
+	kind := Atom
+	for l.pos < len(l.s) && l.s[l.pos] == '.' {
+		dot := l.pos
+		l.pos++
+		if l.atext() == dot+1 {
+			// trailing or repeated dot is not part of dot-atom-text
+			l.pos = dot
+			break
+		}
+		kind = DotAtom
+	}
+	return kind, nil
+}
+
+// atext consumes 0 or more atext or non us-ascii UTF-8, returning new position
+func (l *Lexer) atext() int {
+	for l.pos < len(l.s) {
+		r, size := utf8.DecodeRuneInString(l.s[l.pos:])
+		if !isAtext(r) && (r <= 127 || r == utf8.RuneError) {
+			break
+		}
+		l.pos += size
+	}
+	return l.pos
+}
+
+// encodedWord matches RFC 2047 encoded-word
+var encodedWord = regexp.MustCompile(`^=\?[^?\s()<>@,;:"/\[\]=.]+\?[bBqQ]\?[^?\s]*\?=`)
+
+// encodedWord consumes encoded-word if it is not part of a larger atom
+func (l *Lexer) encodedWord() bool {
+	m := encodedWord.FindString(l.s[l.pos:])
+	end := l.pos + len(m)
+	if end < len(l.s) {
+		if r, _ := utf8.DecodeRuneInString(l.s[end:]); isAtext(r) {
+			return false
+		}
+	}
+	l.pos = end
+	return true
+}
+
+// unquote removes quoted-pair escapes and folding
+func unquote(s string) string {
+	s = unfold(s)
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	sb := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// unfold removes CRLF preceding white space
+func unfold(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "")
+}
+
+// SkipCFWS consumes any folding white space and comments
+//
+//	CFWS            =   (1*([FWS] comment) [FWS]) / FWS
+func (l *Lexer) SkipCFWS() error {
+	for {
+		tok, err := l.Peek()
+		if err != nil {
+			return err
+		}
+		if tok.Kind != FWS && tok.Kind != Comment {
+			return nil
+		}
+		l.Next()
+	}
+}
@@ -0,0 +1,136 @@
+package syntax_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jimtsao/go-email/syntax"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLexer(t *testing.T) {
+	type tok struct {
+		kind  syntax.Kind
+		value string
+		pos   int
+	}
+	for _, c := range []struct {
+		input string
+		want  []tok
+	}{
+		{`"Joe Q. Public" <john.q.public@example.com>`, []tok{
+			{syntax.QuotedString, `"Joe Q. Public"`, 0},
+			{syntax.FWS, " ", 15},
+			{syntax.Special, "<", 16},
+			{syntax.DotAtom, "john.q.public", 17},
+			{syntax.Special, "@", 30},
+			{syntax.DotAtom, "example.com", 31},
+			{syntax.Special, ">", 42},
+		}},
+		{"Pete(A nice \\) chap) <pete(his account)@silly.test(his host)>", []tok{
+			{syntax.Atom, "Pete", 0},
+			{syntax.Comment, "(A nice \\) chap)", 4},
+			{syntax.FWS, " ", 20},
+			{syntax.Special, "<", 21},
+			{syntax.Atom, "pete", 22},
+			{syntax.Comment, "(his account)", 26},
+			{syntax.Special, "@", 39},
+			{syntax.DotAtom, "silly.test", 40},
+			{syntax.Comment, "(his host)", 50},
+			{syntax.Special, ">", 60},
+		}},
+		{"(nested (comment))\r\n\tjdoe@[192.168.0.1]", []tok{
+			{syntax.Comment, "(nested (comment))", 0},
+			{syntax.FWS, "\r\n\t", 18},
+			{syntax.Atom, "jdoe", 21},
+			{syntax.Special, "@", 25},
+			{syntax.DomainLiteral, "[192.168.0.1]", 26},
+		}},
+		{"=?utf-8?q?caf=C3=A9?= =?x?y?z?=w Q. a..b.", []tok{
+			{syntax.EncodedWord, "=?utf-8?q?caf=C3=A9?=", 0},
+			{syntax.FWS, " ", 21},
+			{syntax.Atom, "=?x?y?z?=w", 22},
+			{syntax.FWS, " ", 32},
+			{syntax.Atom, "Q", 33},
+			{syntax.Special, ".", 34},
+			{syntax.FWS, " ", 35},
+			{syntax.Atom, "a", 36},
+			{syntax.Special, ".", 37},
+			{syntax.Special, ".", 38},
+			{syntax.Atom, "b", 39},
+			{syntax.Special, ".", 40},
+		}},
+		{"Zoë:;", []tok{
+			{syntax.Atom, "Zoë", 0},
+			{syntax.Special, ":", 4},
+			{syntax.Special, ";", 5},
+		}},
+	} {
+		tokens, err := syntax.Tokenize(c.input)
+		assert.NoError(t, err, c.input)
+		var got []tok
+		for _, tk := range tokens {
+			got = append(got, tok{tk.Kind, tk.Value, tk.Pos})
+		}
+		assert.Equal(t, c.want, got, c.input)
+	}
+}
+
+func TestLexerErrors(t *testing.T) {
+	for _, c := range []struct {
+		input string
+		pos   int
+	}{
+		{`"unterminated`, 0},
+		{"(unterminated (comment)", 0},
+		{"[unterminated", 0},
+		{"[a[b]", 2},
+		{"a\r\nb", 1},
+		{"a\nb", 1},
+		{"a\x00b", 1},
+		{`"a\`, 2},
+	} {
+		_, err := syntax.Tokenize(c.input)
+		var serr *syntax.SyntaxError
+		if assert.True(t, errors.As(err, &serr), c.input) {
+			assert.Equal(t, c.pos, serr.Pos, c.input)
+		}
+	}
+}
+
+func TestTokenText(t *testing.T) {
+	tokens, err := syntax.Tokenize("\"Joe \\\"Q\\\"\r\n Public\" (a \\(b\\)) \r\n [1.2.3.4]")
+	assert.NoError(t, err)
+	var got []string
+	for _, tk := range tokens {
+		got = append(got, tk.Text())
+	}
+	assert.Equal(t, []string{`Joe "Q" Public`, " ", "a (b)", " ", "[1.2.3.4]"}, got)
+	assert.Equal(t, "quoted-string", syntax.QuotedString.String())
+}
+
+func TestLexerPeek(t *testing.T) {
+	l := syntax.NewLexer("(c) a")
+	assert.NoError(t, l.SkipCFWS())
+	tok, err := l.Peek()
+	assert.NoError(t, err)
+	assert.Equal(t, "a", tok.Value)
+	tok, err = l.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "a", tok.Value)
+	tok, err = l.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, syntax.EOF, tok.Kind)
+	assert.Equal(t, 5, tok.Pos)
+}
+
+func TestParseMsgIDList(t *testing.T) {
+	ids, err := syntax.ParseMsgIDList(" <a.b@c.com> (comment)\r\n <d@[127.0.0.1]><e@f>")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"<a.b@c.com>", "<d@[127.0.0.1]>", "<e@f>"}, ids)
+
+	for _, s := range []string{"", "(only comment)", "a@b.com", "<a@b.com", "<a @b.com>", "<a@[1 .2]>", "<a@b.com> c"} {
+		_, err := syntax.ParseMsgIDList(s)
+		assert.Error(t, err, s)
+	}
+}
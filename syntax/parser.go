@@ -0,0 +1,420 @@
+package syntax
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parser parses structured header field bodies using Lexer. By default
+// only the syntax of RFC 5322 section 3 is accepted. If Lenient, the
+// obsolete syntax of section 4 found in older mail is accepted too, and
+// normalised to its modern form:
+//
+//	obs-angle-addr  =   [CFWS] "<" obs-route addr-spec ">" [CFWS]
+//	obs-route       =   obs-domain-list ":"
+//	obs-local-part  =   word *("." word)
+//	obs-domain      =   atom *("." atom)
+//	obs-phrase      =   word *(word / "." / CFWS)
+//	obs-addr-list   =   *([CFWS] ",") address *("," [address / CFWS])
+//	obs-group-list  =   1*([CFWS] ",") [CFWS]
+//	obs-id-left     =   local-part
+//	obs-id-right    =   domain
+//
+// eg, "John Q. Public <@route:john . public @ example . com>" is
+// parsed as name "John Q. Public" and address "john.public@example.com"
+type Parser struct {
+	Lenient bool
+}
+
+// Mailbox is a mailbox parsed by Parser.AddressList
+type Mailbox struct {
+	Name string // display name, quoted strings are unquoted but encoded-words are not decoded
+	Addr string // addr-spec, local-part is unquoted
+}
+
+// ParseMsgIDList returns each msg-id in the field body of a Message-ID,
+// In-Reply-To or References header, ignoring CFWS between them.
+// Obsolete syntax is not accepted, see Parser
+//
+//	msg-id-list     =   1*msg-id
+//	msg-id          =   [CFWS] "<" id-left "@" id-right ">" [CFWS]
+//	id-left         =   dot-atom-text
+//	id-right        =   dot-atom-text / no-fold-literal
+func ParseMsgIDList(s string) ([]string, error) {
+	return Parser{}.MsgIDList(s)
+}
+
+// MsgIDList returns each msg-id in s, see ParseMsgIDList
+func (p Parser) MsgIDList(s string) ([]string, error) {
+	t, err := newTokens(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for t.peek().Kind != EOF {
+		if tok := t.next(); !isSpecial(tok, "<") {
+			return nil, unexpected(tok, `"<"`)
+		}
+
+		var id string
+		if p.Lenient {
+			local, err := p.localPart(t)
+			if err != nil {
+				return nil, err
+			}
+			if err := t.expect("@"); err != nil {
+				return nil, err
+			}
+			domain, err := p.domain(t)
+			if err != nil {
+				return nil, err
+			}
+			id = quoteLocal(local) + "@" + domain
+		} else if id, err = t.msgID(); err != nil {
+			return nil, err
+		}
+
+		if err := t.expect(">"); err != nil {
+			return nil, err
+		}
+		ids = append(ids, "<"+id+">")
+	}
+
+	if len(ids) == 0 {
+		return nil, unexpected(t.peek(), "msg-id")
+	}
+	return ids, nil
+}
+
+// AddressList returns each mailbox in s, including those of groups
+//
+//	address-list    =   (address *("," address)) / obs-addr-list
+//	address         =   mailbox / group
+//	mailbox         =   name-addr / addr-spec
+//	name-addr       =   [display-name] angle-addr
+//	group           =   display-name ":" [group-list] ";" [CFWS]
+func (p Parser) AddressList(s string) ([]Mailbox, error) {
+	t, err := newTokens(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Mailbox
+	for {
+		// obs-addr-list permits empty elements
+		for p.Lenient && t.isSpecial(",") {
+			t.next()
+		}
+		if p.Lenient && len(list) > 0 && t.peek().Kind == EOF {
+			break
+		}
+
+		mbs, err := p.address(t)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, mbs...)
+
+		if t.peek().Kind == EOF {
+			break
+		}
+		if err := t.expect(","); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+func (p Parser) address(t *tokens) ([]Mailbox, error) {
+	start := t.i
+	words := t.words()
+	if !t.isSpecial(":") {
+		t.i = start
+		mb, err := p.mailbox(t)
+		return []Mailbox{mb}, err
+	}
+
+	// group
+	if _, err := p.phrase(t, start, words); err != nil {
+		return nil, err
+	}
+	t.next()
+	var mbs []Mailbox
+	for !t.isSpecial(";") {
+		if p.Lenient && t.isSpecial(",") {
+			t.next()
+			continue
+		}
+		mb, err := p.mailbox(t)
+		if err != nil {
+			return nil, err
+		}
+		mbs = append(mbs, mb)
+		if t.isSpecial(",") {
+			t.next()
+		} else if !t.isSpecial(";") {
+			return nil, unexpected(t.peek(), `"," or ";"`)
+		}
+	}
+	t.next()
+	return mbs, nil
+}
+
+func (p Parser) mailbox(t *tokens) (Mailbox, error) {
+	start := t.i
+	words := t.words()
+	if t.isSpecial("<") {
+		name, err := p.phrase(t, start, words)
+		if err != nil {
+			return Mailbox{}, err
+		}
+		addr, err := p.angleAddr(t)
+		return Mailbox{Name: name, Addr: addr}, err
+	}
+
+	t.i = start
+	addr, err := p.addrSpec(t)
+	return Mailbox{Addr: addr}, err
+}
+
+// phrase returns display name of words, beginning at token index start
+//
+//	display-name    =   phrase
+//	phrase          =   1*word / obs-phrase
+//	word            =   atom / quoted-string
+func (p Parser) phrase(t *tokens, start int, words []Token) (string, error) {
+	sb := strings.Builder{}
+	for k, w := range words {
+		if isSpecial(w, ".") || w.Kind == DotAtom {
+			if !p.Lenient {
+				return "", obsolete(w, "obs-phrase")
+			} else if k == 0 && w.Kind == Special {
+				return "", unexpected(w, "word")
+			}
+		}
+		if k > 0 && t.space[start+k] {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(w.Text())
+	}
+	return sb.String(), nil
+}
+
+// angleAddr:
+//
+//	angle-addr      =   [CFWS] "<" addr-spec ">" [CFWS] / obs-angle-addr
+//	obs-domain-list =   *(CFWS / ",") "@" domain *("," [CFWS] ["@" domain])
+func (p Parser) angleAddr(t *tokens) (string, error) {
+	t.next()
+	if t.isSpecial("@") || t.isSpecial(",") {
+		if !p.Lenient {
+			return "", obsolete(t.peek(), "obs-route")
+		}
+		// route is discarded
+		for !t.isSpecial(":") {
+			if tok := t.next(); tok.Kind == EOF || isSpecial(tok, ">") {
+				return "", unexpected(tok, `":"`)
+			}
+		}
+		t.next()
+	}
+
+	addr, err := p.addrSpec(t)
+	if err != nil {
+		return "", err
+	}
+	return addr, t.expect(">")
+}
+
+// addrSpec:
+//
+//	addr-spec       =   local-part "@" domain
+func (p Parser) addrSpec(t *tokens) (string, error) {
+	local, err := p.localPart(t)
+	if err != nil {
+		return "", err
+	}
+	if err := t.expect("@"); err != nil {
+		return "", err
+	}
+	domain, err := p.domain(t)
+	if err != nil {
+		return "", err
+	}
+	return local + "@" + domain, nil
+}
+
+// localPart returns local-part, unquoted:
+//
+//	local-part      =   dot-atom / quoted-string / obs-local-part
+func (p Parser) localPart(t *tokens) (string, error) {
+	words := t.words()
+	switch {
+	case len(words) == 0:
+		return "", unexpected(t.peek(), "local-part")
+	case len(words) == 1 && (words[0].Kind == Atom || words[0].Kind == DotAtom || words[0].Kind == QuotedString):
+		return words[0].Text(), nil
+	case !p.Lenient:
+		return "", obsolete(words[0], "obs-local-part")
+	}
+	return dotted(words, "local-part")
+}
+
+// domain:
+//
+//	domain          =   dot-atom / domain-literal / obs-domain
+func (p Parser) domain(t *tokens) (string, error) {
+	if t.peek().Kind == DomainLiteral {
+		return t.next().Text(), nil
+	}
+
+	words := t.words()
+	switch {
+	case len(words) == 0:
+		return "", unexpected(t.peek(), "domain")
+	case len(words) == 1 && (words[0].Kind == Atom || words[0].Kind == DotAtom):
+		return words[0].Value, nil
+	case !p.Lenient:
+		return "", obsolete(words[0], "obs-domain")
+	}
+	for _, w := range words {
+		if w.Kind == QuotedString {
+			return "", unexpected(w, "atom")
+		}
+	}
+	return dotted(words, "domain")
+}
+
+// dotted joins words separated by "."
+func dotted(words []Token, what string) (string, error) {
+	sb := strings.Builder{}
+	for k, w := range words {
+		if isSpecial(w, ".") != (k%2 == 1) {
+			return "", unexpected(w, what)
+		}
+		sb.WriteString(w.Text())
+	}
+	if last := words[len(words)-1]; isSpecial(last, ".") {
+		return "", unexpected(last, what)
+	}
+	return sb.String(), nil
+}
+
+// quoteLocal returns local-part as dot-atom-text if possible,
+// otherwise as a quoted-string
+func quoteLocal(s string) string {
+	if IsDotAtomText(s) {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// tokens is a token stream with CFWS removed, for parsing
+type tokens struct {
+	toks  []Token
+	space []bool // CFWS preceeds token
+	i     int
+	end   int // input length, position of EOF
+}
+
+func newTokens(s string) (*tokens, error) {
+	all, err := Tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	t := &tokens{end: len(s)}
+	space := false
+	for _, tok := range all {
+		if tok.Kind == FWS || tok.Kind == Comment {
+			space = true
+			continue
+		}
+		t.toks = append(t.toks, tok)
+		t.space = append(t.space, space)
+		space = false
+	}
+	return t, nil
+}
+
+func (t *tokens) peek() Token {
+	if t.i < len(t.toks) {
+		return t.toks[t.i]
+	}
+	return Token{Kind: EOF, Pos: t.end}
+}
+
+func (t *tokens) next() Token {
+	tok := t.peek()
+	if t.i < len(t.toks) {
+		t.i++
+	}
+	return tok
+}
+
+func (t *tokens) isSpecial(v string) bool {
+	return isSpecial(t.peek(), v)
+}
+
+// expect consumes special v or returns an error
+func (t *tokens) expect(v string) error {
+	if tok := t.next(); !isSpecial(tok, v) {
+		return unexpected(tok, fmt.Sprintf("%q", v))
+	}
+	return nil
+}
+
+// words consumes a run of words and dots, eg a phrase or local-part
+func (t *tokens) words() []Token {
+	start := t.i
+	for t.i < len(t.toks) {
+		switch tok := t.toks[t.i]; tok.Kind {
+		case Atom, DotAtom, QuotedString, EncodedWord:
+		default:
+			if !isSpecial(tok, ".") {
+				return t.toks[start:t.i]
+			}
+		}
+		t.i++
+	}
+	return t.toks[start:t.i]
+}
+
+// msgID consumes id-left "@" id-right ">" without CFWS
+func (t *tokens) msgID() (string, error) {
+	for k := 0; k < 4; k++ {
+		if t.i+k < len(t.toks) && t.space[t.i+k] {
+			return "", &SyntaxError{Pos: t.toks[t.i+k].Pos, Msg: "white space not permitted within msg-id"}
+		}
+	}
+
+	left := t.next()
+	if left.Kind != Atom && left.Kind != DotAtom {
+		return "", unexpected(left, "id-left")
+	}
+	if err := t.expect("@"); err != nil {
+		return "", err
+	}
+	right := t.next()
+	if right.Kind != Atom && right.Kind != DotAtom &&
+		!(right.Kind == DomainLiteral && IsNoFoldLiteral(right.Value)) {
+		return "", unexpected(right, "id-right")
+	}
+	return left.Value + "@" + right.Value, nil
+}
+
+func isSpecial(tok Token, v string) bool {
+	return tok.Kind == Special && tok.Value == v
+}
+
+func unexpected(tok Token, want string) error {
+	if tok.Kind == EOF {
+		return &SyntaxError{Pos: tok.Pos, Msg: "unexpected end, expected " + want}
+	}
+	return &SyntaxError{Pos: tok.Pos, Msg: fmt.Sprintf("unexpected %s %q, expected %s", tok.Kind, tok.Value, want)}
+}
+
+func obsolete(tok Token, rule string) error {
+	return &SyntaxError{Pos: tok.Pos, Msg: fmt.Sprintf("obsolete syntax (%s) not permitted", rule)}
+}
@@ -0,0 +1,79 @@
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/jimtsao/go-email/syntax"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParserAddressList(t *testing.T) {
+	for _, c := range []struct {
+		input string
+		want  []syntax.Mailbox
+	}{
+		{"a@b.com", []syntax.Mailbox{{Addr: "a@b.com"}}},
+		{`"Joe Q. Public" <john.q.public@example.com>, Mary Smith <mary@x.test>`, []syntax.Mailbox{
+			{Name: "Joe Q. Public", Addr: "john.q.public@example.com"},
+			{Name: "Mary Smith", Addr: "mary@x.test"}}},
+		{"Pete(A nice \\) chap) <pete(his account)@silly.test(his host)>", []syntax.Mailbox{
+			{Name: "Pete", Addr: "pete@silly.test"}}},
+		{`A Group:Ed Jones <c@a.test>,joe@where.test,"John" <jdoe@one.test>;, Undisclosed:;`, []syntax.Mailbox{
+			{Name: "Ed Jones", Addr: "c@a.test"},
+			{Addr: "joe@where.test"},
+			{Name: "John", Addr: "jdoe@one.test"}}},
+		{`"a b"@[127.0.0.1]`, []syntax.Mailbox{{Addr: "a b@[127.0.0.1]"}}},
+		{"=?utf-8?q?caf=C3=A9?= <c@d.com>", []syntax.Mailbox{{Name: "=?utf-8?q?caf=C3=A9?=", Addr: "c@d.com"}}},
+	} {
+		for _, lenient := range []bool{false, true} {
+			got, err := syntax.Parser{Lenient: lenient}.AddressList(c.input)
+			assert.NoError(t, err, c.input)
+			assert.Equal(t, c.want, got, c.input)
+		}
+	}
+}
+
+func TestParserObsolete(t *testing.T) {
+	for _, c := range []struct {
+		input string
+		want  []syntax.Mailbox
+	}{
+		// obs-route
+		{"<@a.com,@b.com:john@c.com>", []syntax.Mailbox{{Addr: "john@c.com"}}},
+		{"Jim <,@a.com:jim@c.com>", []syntax.Mailbox{{Name: "Jim", Addr: "jim@c.com"}}},
+		// obs-local-part, obs-domain
+		{"john . doe @ example . com", []syntax.Mailbox{{Addr: "john.doe@example.com"}}},
+		{`"john".doe(c)@example.com`, []syntax.Mailbox{{Addr: "john.doe@example.com"}}},
+		// obs-phrase
+		{"John Q. Public <jqp@x.com>", []syntax.Mailbox{{Name: "John Q. Public", Addr: "jqp@x.com"}}},
+		{"J.Q.Public <jqp@x.com>", []syntax.Mailbox{{Name: "J.Q.Public", Addr: "jqp@x.com"}}},
+		// obs-addr-list, obs-group-list
+		{", ,a@b.com,,c@d.com,", []syntax.Mailbox{{Addr: "a@b.com"}, {Addr: "c@d.com"}}},
+		{"G:, ,a@b.com,;", []syntax.Mailbox{{Addr: "a@b.com"}}},
+	} {
+		got, err := syntax.Parser{Lenient: true}.AddressList(c.input)
+		assert.NoError(t, err, c.input)
+		assert.Equal(t, c.want, got, c.input)
+
+		_, err = syntax.Parser{}.AddressList(c.input)
+		assert.Error(t, err, c.input)
+	}
+
+	for _, s := range []string{"", ",", "a@b.com c@d.com", "<a@b.com", ". Q <a@b.com>",
+		"john..doe@example.com", "a@b.", `a@"b".com`, "<@a.com a@b.com>", "G:a@b.com"} {
+		_, err := syntax.Parser{Lenient: true}.AddressList(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParserMsgIDList(t *testing.T) {
+	p := syntax.Parser{Lenient: true}
+	ids, err := p.MsgIDList("<a . b @ c . com> <\"x y\"@[1.2.3.4]>(c)<d@e>")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"<a.b@c.com>", `<"x y"@[1.2.3.4]>`, "<d@e>"}, ids)
+
+	for _, s := range []string{"", "<a . b @ c . com>", `<"x y"@c.com>`} {
+		_, err := syntax.ParseMsgIDList(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -265,9 +265,7 @@ func isWordEncodable(r rune) bool {
 //	dot-atom-text = 1*atext *("." 1*atext)
 func IsDotAtomText(s string) bool {
 	dot := true
-	var i int
-	var r rune
-	for i, r = range s {
+	for i, r := range s {
 		if r == '.' && (i == 0 || i == len(s)-1) {
 			return false
 		}
@@ -278,7 +276,7 @@ func IsDotAtomText(s string) bool {
 	}
 
 	// must be at least 1 valid character
-	return i != 0
+	return s != ""
 }
 
 func isDotAtomText(r rune, dot bool) bool {
@@ -152,6 +152,8 @@ func TestIsDotAtomText(t *testing.T) {
 		"2024.01.24": true,
 		"127.0.0.1":  true,
 		"d.ot":       true,
+		"d":          true,
+		"":           false,
 		".dot":       false,
 		"dot.":       false,
 		"dot..dot":   false,
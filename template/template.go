@@ -0,0 +1,194 @@
+// package template renders personalised emails from templates, using
+// text/template for the subject and text body and html/template for
+// the html body, so that data is escaped according to its context
+package template
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+
+	goemail "github.com/jimtsao/go-email"
+)
+
+// File name suffixes of message templates
+const (
+	SubjectExt = ".subject.tmpl"
+	TextExt    = ".txt.tmpl"
+	HTMLExt    = ".html.tmpl"
+)
+
+// Template renders the subject and bodies of a message. It is
+// safe for concurrent use once parsed
+type Template struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// New parses template source for each part of a message,
+// empty sources are omitted from the rendered message
+//
+// usage:
+//
+//	t, err := New("Welcome {{.Name}}", "Hi {{.Name}}", "<p>Hi {{.Name}}</p>")
+func New(subject string, text string, html string) (*Template, error) {
+	return newTemplate("message", subject, text, html, nil, nil)
+}
+
+// Must panics if err is non nil, for use in variable initialisation
+func Must(t *Template, err error) *Template {
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Set is a collection of message templates in a file system,
+// sharing layouts, partials and functions
+//
+// A message named "welcome" consists of any of the files:
+//
+//	welcome.subject.tmpl
+//	welcome.txt.tmpl
+//	welcome.html.tmpl
+//
+// Files matching Layouts are parsed alongside each message body
+// template of the same kind, so a body may define blocks used by a
+// layout or invoke partials by name. Layout file names must end in
+// .txt.tmpl or .html.tmpl to determine their kind
+type Set struct {
+	FS      fs.FS
+	Layouts []string // glob patterns, eg "layouts/*.tmpl" matching base.html.tmpl
+	Funcs   map[string]interface{}
+}
+
+// Parse parses templates for the named message, at least one
+// of text or html body must be present
+func (s *Set) Parse(name string) (*Template, error) {
+	read := func(ext string) (string, error) {
+		b, err := fs.ReadFile(s.FS, name+ext)
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		return string(b), err
+	}
+
+	subject, err := read(SubjectExt)
+	if err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+	text, err := read(TextExt)
+	if err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+	html, err := read(HTMLExt)
+	if err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+
+	layouts := map[string]string{}
+	for _, pattern := range s.Layouts {
+		matches, err := fs.Glob(s.FS, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("template: %w", err)
+		}
+		for _, m := range matches {
+			if !strings.HasSuffix(m, TextExt) && !strings.HasSuffix(m, HTMLExt) {
+				return nil, fmt.Errorf("template: layout %s: name must end in %s or %s", m, TextExt, HTMLExt)
+			}
+			b, err := fs.ReadFile(s.FS, m)
+			if err != nil {
+				return nil, fmt.Errorf("template: %w", err)
+			}
+			layouts[m] = string(b)
+		}
+	}
+
+	return newTemplate(path.Base(name), subject, text, html, layouts, s.Funcs)
+}
+
+func newTemplate(name string, subject string, text string, html string, layouts map[string]string, funcs map[string]interface{}) (*Template, error) {
+	if text == "" && html == "" {
+		return nil, fmt.Errorf("template: %s: missing text or html body", name)
+	}
+
+	t := &Template{}
+	var err error
+	if subject != "" {
+		t.subject = texttemplate.New(name + SubjectExt).Option("missingkey=error").Funcs(funcs)
+		if _, err = t.subject.Parse(subject); err != nil {
+			return nil, fmt.Errorf("template: %w", err)
+		}
+	}
+
+	if text != "" {
+		t.text = texttemplate.New(name + TextExt).Option("missingkey=error").Funcs(funcs)
+		if _, err = t.text.Parse(text); err != nil {
+			return nil, fmt.Errorf("template: %w", err)
+		}
+		for file, src := range layouts {
+			if strings.HasSuffix(file, TextExt) {
+				if _, err = t.text.New(file).Parse(src); err != nil {
+					return nil, fmt.Errorf("template: %w", err)
+				}
+			}
+		}
+	}
+
+	if html != "" {
+		t.html = htmltemplate.New(name + HTMLExt).Option("missingkey=error").Funcs(funcs)
+		if _, err = t.html.Parse(html); err != nil {
+			return nil, fmt.Errorf("template: %w", err)
+		}
+		for file, src := range layouts {
+			if strings.HasSuffix(file, HTMLExt) {
+				if _, err = t.html.New(file).Parse(src); err != nil {
+					return nil, fmt.Errorf("template: %w", err)
+				}
+			}
+		}
+	}
+
+	return t, nil
+}
+
+// Render executes templates with data, returning an Email with Subject,
+// Text and HTML Body set, ready for addressing. Body is labelled text/html
+// regardless of its content. The subject is collapsed
+// onto a single line, as line breaks are not permitted in header fields
+func (t *Template) Render(data interface{}) (*goemail.Email, error) {
+	e := goemail.New()
+	buf := &bytes.Buffer{}
+
+	if t.subject != nil {
+		if err := t.subject.Execute(buf, data); err != nil {
+			return nil, fmt.Errorf("template: %w", err)
+		}
+		e.Subject = strings.Join(strings.Fields(buf.String()), " ")
+		buf.Reset()
+	}
+
+	if t.text != nil {
+		if err := t.text.Execute(buf, data); err != nil {
+			return nil, fmt.Errorf("template: %w", err)
+		}
+		e.Text = buf.String()
+		buf.Reset()
+	}
+
+	if t.html != nil {
+		if err := t.html.Execute(buf, data); err != nil {
+			return nil, fmt.Errorf("template: %w", err)
+		}
+		e.Body = buf.String()
+		e.BodyType = "text/html"
+	}
+
+	return e, nil
+}
@@ -0,0 +1,103 @@
+package template_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jimtsao/go-email/template"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Name  string
+	Items []string
+}
+
+func TestNew(t *testing.T) {
+	tmpl, err := template.New(
+		"Welcome\r\n{{.Name}}",
+		"Hi {{.Name}}",
+		"<p>Hi {{.Name}}</p>")
+	assert.NoError(t, err)
+
+	e, err := tmpl.Render(user{Name: "<Bob>"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome <Bob>", e.Subject, "subject on single line")
+	assert.Equal(t, "Hi <Bob>", e.Text, "text is not escaped")
+	assert.Equal(t, "<p>Hi &lt;Bob&gt;</p>", e.Body, "html is escaped")
+
+	// subject header injection
+	e, err = tmpl.Render(user{Name: "Bob\r\nBcc: eve@e.com"})
+	assert.NoError(t, err)
+	assert.Contains(t, e.Raw(), "\r\nSubject: Welcome Bob Bcc: eve@e.com\r\n")
+
+	// html fragment is labelled html
+	tmpl = template.Must(template.New("", "", "Hi <b>{{.Name}}</b>"))
+	e, err = tmpl.Render(user{Name: "Bob"})
+	assert.NoError(t, err)
+	assert.Contains(t, e.Raw(), "Content-Type: text/html; charset=utf-8\r\n\r\nHi <b>Bob</b>")
+
+	// text only
+	tmpl = template.Must(template.New("", "Hi {{.Name}}", ""))
+	e, err = tmpl.Render(user{Name: "Bob"})
+	assert.NoError(t, err)
+	assert.Empty(t, e.Subject)
+	assert.Empty(t, e.Body)
+	assert.Contains(t, e.Raw(), "Content-Type: text/plain; charset=utf-8\r\n\r\nHi Bob")
+
+	// errors
+	_, err = template.New("subject", "", "")
+	assert.Error(t, err, "no body")
+	_, err = template.New("{{.Name", "text", "")
+	assert.Error(t, err, "parse error")
+	_, err = tmpl.Render(map[string]string{})
+	assert.Error(t, err, "missing key")
+	assert.Panics(t, func() { template.Must(template.New("", "", "")) })
+}
+
+func TestSet(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layouts/base.html.tmpl":    {Data: []byte(`<html><body>{{block "content" .}}{{end}}{{template "footer.html" .}}</body></html>`)},
+		"layouts/footer.html.tmpl":  {Data: []byte(`{{define "footer.html"}}<p>{{upper "bye"}}</p>{{end}}`)},
+		"layouts/footer.txt.tmpl":   {Data: []byte(`{{define "footer.txt"}}-- {{upper "bye"}}{{end}}`)},
+		"emails/order.subject.tmpl": {Data: []byte(`Order for {{.Name}}`)},
+		"emails/order.txt.tmpl": {Data: []byte(`{{range .Items}}* {{.}}
+{{end}}{{template "footer.txt"}}`)},
+		"emails/order.html.tmpl": {Data: []byte(`{{define "content"}}<ul>{{range .Items}}<li>{{.}}</li>{{end}}</ul>{{end}}{{template "layouts/base.html.tmpl" .}}`)},
+	}
+	set := &template.Set{
+		FS:      fsys,
+		Layouts: []string{"layouts/*.tmpl"},
+		Funcs:   map[string]interface{}{"upper": strings.ToUpper},
+	}
+
+	tmpl, err := set.Parse("emails/order")
+	assert.NoError(t, err)
+	e, err := tmpl.Render(user{Name: "Bob", Items: []string{"tea", "cake & jam"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Order for Bob", e.Subject)
+	assert.Equal(t, "* tea\n* cake & jam\n-- BYE", e.Text)
+	assert.Equal(t, "<html><body><ul><li>tea</li><li>cake &amp; jam</li></ul><p>BYE</p></body></html>", e.Body)
+
+	// multipart/alternative
+	e.From = "shop@a.com"
+	e.To = "bob@b.com"
+	raw := e.Raw()
+	assert.Contains(t, raw, "Content-Type: multipart/alternative;")
+	assert.Less(t, strings.Index(raw, "text/plain"), strings.Index(raw, "text/html"))
+
+	// missing message
+	_, err = set.Parse("emails/missing")
+	assert.Error(t, err)
+
+	// layout of unknown kind
+	fsys["layouts/base.tmpl"] = &fstest.MapFile{Data: []byte(`{{block "content" .}}{{end}}`)}
+	_, err = set.Parse("emails/order")
+	assert.Error(t, err)
+
+	// invalid layout pattern
+	set.Layouts = []string{"["}
+	_, err = set.Parse("emails/order")
+	assert.Error(t, err)
+}